@@ -0,0 +1,207 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fatih/color"
+	"gitlab.ozon.ru/validator/goexel"
+	"gitlab.ozon.ru/validator/platform"
+)
+
+const (
+	batchPassed = "passed"
+	batchFailed = "failed"
+	// batchBroken - файл не удалось провалидировать: не открылся, не декодировался, упал пайплайн
+	batchBroken = "broken"
+)
+
+// batchSummary - сводка по всем файлам папки
+type batchSummary struct {
+	Files      int                     `json:"files"`
+	Passed     int                     `json:"passed"`
+	Failed     int                     `json:"failed"`
+	Broken     int                     `json:"broken"`
+	BySeverity map[goexel.Severity]int `json:"by_severity"`
+	ByJob      map[string]int          `json:"by_job"`
+	Results    []batchFile             `json:"results"`
+}
+
+type batchFile struct {
+	File     string `json:"file"`
+	Status   string `json:"status"`
+	Findings int    `json:"findings"`
+	Error    string `json:"error,omitempty"`
+}
+
+// isBatch - вместо файла передали папку или glob
+func isBatch(target string) bool {
+	if strings.ContainsAny(target, "*?[") {
+		return true
+	}
+	info, err := os.Stat(target)
+	return err == nil && info.IsDir()
+}
+
+// batchFiles - файлы для валидации из папки или по glob, наши же результаты прошлых запусков пропускаем
+func batchFiles(target string) ([]string, error) {
+	pattern := target
+	if info, err := os.Stat(target); err == nil && info.IsDir() {
+		pattern = filepath.Join(target, "*")
+	}
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]string, 0, len(matches))
+	for _, file := range matches {
+		name := filepath.Base(file)
+		base := strings.TrimSuffix(name, filepath.Ext(name))
+		switch {
+		case strings.HasPrefix(name, "~$"), strings.HasSuffix(base, "_new_val_comm"):
+			continue
+		}
+		switch strings.ToLower(filepath.Ext(name)) {
+		case ".xlsx", ".csv", ".tsv", ".tab":
+		default:
+			continue
+		}
+		if info, err := os.Stat(file); err != nil || info.IsDir() {
+			continue
+		}
+		res = append(res, file)
+	}
+	sort.Strings(res)
+	return res, nil
+}
+
+// validateBatch - по пайплайну на файл на общей платформе, одновременно не больше opts.parallel файлов.
+// Размеченные файлы и отчеты ложатся рядом с исходными, сводка в лог и в opts.out, если он задан.
+// false - хоть один файл не прошел валидацию или не прочитался
+func validateBatch(ctx context.Context, plat *platform.Platform, jobIDs []platform.JobID, target string, opts cliOptions) bool {
+	files, err := batchFiles(target)
+	if err != nil {
+		log.Fatalf("failed to list files: %s", color.RedString(err.Error()))
+	}
+	if len(files) == 0 {
+		log.Fatalf("no files to validate in %s", color.RedString(target))
+	}
+	start := time.Now()
+
+	// --out в пакетном режиме - путь сводки, файлы пишутся каждый рядом со своим
+	fileOpts := opts
+	fileOpts.out = ""
+	parallel := opts.parallel
+	if parallel <= 0 {
+		parallel = 1
+	}
+
+	var (
+		results = make([]batchFile, len(files))
+		reports = make([]goexel.Report, len(files))
+		wg      sync.WaitGroup
+		sem     = make(chan struct{}, parallel)
+	)
+	for i, file := range files {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, file string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			results[i].File = file
+			res, err := validateFile(ctx, plat, jobIDs, file, fileOpts, false)
+			switch {
+			case err != nil:
+				results[i].Status = batchBroken
+				results[i].Error = err.Error()
+				log.Printf("%s: %s", file, color.RedString(err.Error()))
+				return
+			case res.hasErrors:
+				results[i].Status = batchFailed
+			default:
+				results[i].Status = batchPassed
+			}
+			results[i].Findings = res.report.Total
+			reports[i] = res.report
+			log.Printf("%s: %s, findings: %d", file, results[i].Status, res.report.Total)
+		}(i, file)
+	}
+	wg.Wait()
+
+	summary := batchSummary{
+		Files:      len(files),
+		BySeverity: make(map[goexel.Severity]int),
+		ByJob:      make(map[string]int),
+		Results:    results,
+	}
+	for i, res := range results {
+		switch res.Status {
+		case batchPassed:
+			summary.Passed++
+		case batchFailed:
+			summary.Failed++
+		case batchBroken:
+			summary.Broken++
+		}
+		for severity, count := range reports[i].BySeverity {
+			summary.BySeverity[severity] += count
+		}
+		for jobID, count := range reports[i].ByJob {
+			summary.ByJob[jobID] += count
+		}
+	}
+
+	if opts.out != "" {
+		body, err := json.MarshalIndent(summary, "", "  ")
+		if err != nil {
+			log.Fatalf("failed to build summary: %s", color.RedString(err.Error()))
+		}
+		//nolint:gosec
+		if err = os.WriteFile(opts.out, body, 0666); err != nil {
+			log.Fatalf("failed to save summary: %s", color.RedString(err.Error()))
+		}
+		log.Printf("summary has been saved to %s ", color.BlackString(opts.out))
+	}
+
+	jobIDsByCount := make([]string, 0, len(summary.ByJob))
+	for jobID := range summary.ByJob {
+		jobIDsByCount = append(jobIDsByCount, jobID)
+	}
+	sort.Slice(jobIDsByCount, func(i, j int) bool {
+		if summary.ByJob[jobIDsByCount[i]] != summary.ByJob[jobIDsByCount[j]] {
+			return summary.ByJob[jobIDsByCount[i]] > summary.ByJob[jobIDsByCount[j]]
+		}
+		return jobIDsByCount[i] < jobIDsByCount[j]
+	})
+	sb := &strings.Builder{}
+	fmt.Fprintf(sb, "files: %d\npassed: %s\nfailed: %s\nbroken: %s\n",
+		summary.Files,
+		color.GreenString("%d", summary.Passed),
+		color.RedString("%d", summary.Failed),
+		color.MagentaString("%d", summary.Broken),
+	)
+	fmt.Fprintf(sb, "errors: %s\nwarnings: %s\ninfo: %s",
+		color.RedString("%d", summary.BySeverity[goexel.SeverityError]),
+		color.YellowString("%d", summary.BySeverity[goexel.SeverityWarning]),
+		color.BlueString("%d", summary.BySeverity[goexel.SeverityInfo]),
+	)
+	for _, jobID := range jobIDsByCount {
+		fmt.Fprintf(sb, "\n  %s: %d", jobID, summary.ByJob[jobID])
+	}
+	log.Printf(boundedStrLayout, sb.String())
+	log.Printf(boundedStrLayout, fmt.Sprintf("end of validation:\ntime is:  %s", color.GreenString("%f", time.Since(start).Seconds())))
+
+	return summary.Failed == 0 && summary.Broken == 0
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"gitlab.ozon.ru/validator/platform"
+)
+
+func TestBatchFiles(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"b.csv", "a.xlsx", "~$a.xlsx", "a_new_val_comm.xlsx", "a_report.json", "notes.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(dir, "done.xlsx"), 0777); err != nil {
+		t.Fatal(err)
+	}
+
+	for target, expected := range map[string][]string{
+		dir:                          {"a.xlsx", "b.csv"},
+		filepath.Join(dir, "*.xlsx"): {"a.xlsx"},
+	} {
+		files, err := batchFiles(target)
+		if err != nil {
+			t.Fatal(err)
+		}
+		names := make([]string, 0, len(files))
+		for _, file := range files {
+			names = append(names, filepath.Base(file))
+		}
+		if !reflect.DeepEqual(names, expected) {
+			t.Fatalf("%s: expected %v, got %v", target, expected, names)
+		}
+	}
+}
+
+func TestValidateBatch(t *testing.T) {
+	dir := t.TempDir()
+	writeEntryBook(t, dir, "march.xlsx", 0, "5", "1")
+	writeEntryBook(t, dir, "april.xlsx", 0, "7")
+	summaryFile := filepath.Join(t.TempDir(), "summary.json")
+
+	plat, _ := newPlatform("", nil)
+	if validateBatch(context.Background(), plat, []platform.JobID{"Валидный ли Ску"}, dir, cliOptions{out: summaryFile, parallel: 2}) {
+		t.Fatal("a batch with an invalid file must fail")
+	}
+
+	body, err := os.ReadFile(summaryFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var summary batchSummary
+	if err = json.Unmarshal(body, &summary); err != nil {
+		t.Fatal(err)
+	}
+	// пустой СКУ в march - ошибка, файл не проходит
+	if summary.Files != 2 || summary.Passed != 1 || summary.Failed != 1 || summary.ByJob["Валидный ли Ску"] != 1 {
+		t.Fatalf("unexpected summary %+v", summary)
+	}
+	// пайплайны файлов не копятся в реестре общей платформы
+	if pipes := plat.ListPipelines(); len(pipes) != 0 {
+		t.Fatalf("%d pipelines are left in the registry", len(pipes))
+	}
+}
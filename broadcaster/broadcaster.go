@@ -2,43 +2,252 @@ package broadcaster
 
 import (
 	"context"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/pkg/errors"
 	"gitlab.ozon.ru/validator/platform"
 )
 
+// buffer - буфер канала подписчика по умолчанию
 const buffer int = 0
 
+// Broadcaster - рассылает результаты всем подписчикам. Sub, Send и Close можно звать из разных горутин
 type Broadcaster[T any] struct {
-	casts []chan T
-	buf   int
+	opts options
+
+	// mu - охраняет подписчиков, историю и closed, держится недолго
+	mu      sync.Mutex
+	subs    []*subscriber[T]
+	history []T
+	closed  bool
+	// sendMu - Close ждет, пока закончатся идущие Send, чтобы не писать в закрытые каналы
+	sendMu sync.RWMutex
+}
+
+// subscriber - канал подписчика и сколько Send его ждали
+type subscriber[T any] struct {
+	name string
+	ch   chan T
+
+	sends       int64
+	blocked     int64
+	blockedTime int64
+	maxBlocked  int64
+}
+
+type options struct {
+	buf int
+	// subBuf - буферы отдельных подписчиков по имени, перекрывают buf
+	subBuf map[string]int
+	// replay - хранить отправленное для поздних подписчиков, replayLimit - сколько последних, 0 - все
+	replay      bool
+	replayLimit int
+
+	// дальше настройки только для RedisBroadcaster
+	stream       string
+	streamPrefix string
+	maxLen       int64
+	ttl          time.Duration
+	block        time.Duration
+}
+
+type opt func(o *options)
+
+// WithBuffer - размер буфера канала каждого подписчика
+func WithBuffer(size int) opt {
+	return func(o *options) {
+		o.buf = size
+	}
+}
+
+// WithSubscriberBuffer - свой буфер для подписчика name, например батчевой джобе буфер на целый батч
+func WithSubscriberBuffer(name string, size int) opt {
+	return func(o *options) {
+		if o.subBuf == nil {
+			o.subBuf = make(map[string]int)
+		}
+		o.subBuf[name] = size
+	}
+}
+
+// WithReplay - подписчик, пришедший после начала рассылки, сначала получит уже отправленное.
+// limit - сколько последних результатов хранить, 0 - все
+func WithReplay(limit int) opt {
+	return func(o *options) {
+		o.replay = true
+		o.replayLimit = limit
+	}
+}
+
+// NewBroadcaster - прототип броадкастера, настройки переходят во все копии из Create
+func NewBroadcaster[T any](opts ...opt) *Broadcaster[T] {
+	res := &Broadcaster[T]{opts: options{buf: buffer}}
+	for _, opt := range opts {
+		opt(&res.opts)
+	}
+	return res
 }
 
 func (b *Broadcaster[T]) Sub() chan T {
-	ch := make(chan T, b.buf)
-	b.casts = append(b.casts, ch)
-	return ch
+	return b.SubNamed("")
+}
+
+// SubNamed - Sub, по имени подписчика выбирается буфер и ведется статистика ожиданий.
+// С WithReplay в канал сразу кладется история, поэтому буфер у него больше на ее размер,
+// а после Close подписчик получит историю и закрытый канал
+func (b *Broadcaster[T]) SubNamed(name string) chan T {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	size := b.opts.buf
+	if subBuf, exists := b.opts.subBuf[name]; exists {
+		size = subBuf
+	}
+	sub := &subscriber[T]{name: name, ch: make(chan T, size+len(b.history))}
+	for _, obj := range b.history {
+		sub.ch <- obj
+	}
+	if b.closed {
+		close(sub.ch)
+		return sub.ch
+	}
+	b.subs = append(b.subs, sub)
+	return sub.ch
 }
 
+// Send - отдает obj всем подписчикам. Сначала кладем в каналы, где есть место,
+// а тех, кто не успевает, ждем параллельно, чтобы медленный подписчик не задерживал остальных
 func (b *Broadcaster[T]) Send(ctx context.Context, obj T) error {
-	for _, ch := range b.casts {
+	b.sendMu.RLock()
+	defer b.sendMu.RUnlock()
+
+	// подписчики и история меняются вместе, так что подписавшийся в этот момент
+	// получит obj либо из истории, либо рассылкой, но не дважды
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return errors.Wrap(platform.ErrFatal, "send on closed broadcaster")
+	}
+	subs := b.subs
+	b.remember(obj)
+	b.mu.Unlock()
+
+	var waiting []*subscriber[T]
+	for _, sub := range subs {
+		atomic.AddInt64(&sub.sends, 1)
 		select {
-		case <-ctx.Done():
-			return errors.Wrap(platform.ErrFatal, ctx.Err().Error())
-		case ch <- obj:
+		case sub.ch <- obj:
+		default:
+			waiting = append(waiting, sub)
+		}
+	}
+
+	switch len(waiting) {
+	case 0:
+		return nil
+	case 1:
+		return waiting[0].send(ctx, obj)
+	}
+
+	var (
+		wg   sync.WaitGroup
+		errs = make([]error, len(waiting))
+	)
+	for i, sub := range waiting {
+		wg.Add(1)
+		go func(i int, sub *subscriber[T]) {
+			defer wg.Done()
+			errs[i] = sub.send(ctx, obj)
+		}(i, sub)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// remember - история для WithReplay, вызывать под mu
+func (b *Broadcaster[T]) remember(obj T) {
+	if !b.opts.replay {
+		return
+	}
+	b.history = append(b.history, obj)
+	if limit := b.opts.replayLimit; limit > 0 && len(b.history) > limit {
+		// сдвигаем окно, а массив под ним переаллоцируем, только когда он вдвое больше нужного
+		if cap(b.history) > 2*limit {
+			b.history = append(make([]T, 0, limit), b.history[len(b.history)-limit:]...)
+		} else {
+			b.history = b.history[len(b.history)-limit:]
 		}
 	}
+}
+
+// send - блокирующая отправка, время ожидания идет в статистику подписчика
+func (s *subscriber[T]) send(ctx context.Context, obj T) error {
+	start := time.Now()
+	defer func() {
+		blocked := int64(time.Since(start))
+		atomic.AddInt64(&s.blocked, 1)
+		atomic.AddInt64(&s.blockedTime, blocked)
+		for {
+			prev := atomic.LoadInt64(&s.maxBlocked)
+			if blocked <= prev || atomic.CompareAndSwapInt64(&s.maxBlocked, prev, blocked) {
+				break
+			}
+		}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return errors.Wrap(platform.ErrFatal, ctx.Err().Error())
+	case s.ch <- obj:
+	}
 	return nil
 }
 
+// BlockStats - сколько Send ждали каждого подписчика, в порядке подписки
+func (b *Broadcaster[T]) BlockStats() []platform.SubscriberStats {
+	b.mu.Lock()
+	subs := b.subs
+	b.mu.Unlock()
+
+	res := make([]platform.SubscriberStats, 0, len(subs))
+	for _, sub := range subs {
+		res = append(res, platform.SubscriberStats{
+			Subscriber:  sub.name,
+			Buffer:      cap(sub.ch),
+			Sends:       atomic.LoadInt64(&sub.sends),
+			Blocked:     atomic.LoadInt64(&sub.blocked),
+			BlockedTime: time.Duration(atomic.LoadInt64(&sub.blockedTime)),
+			MaxBlocked:  time.Duration(atomic.LoadInt64(&sub.maxBlocked)),
+		})
+	}
+	return res
+}
+
+// Close - закрывает каналы подписчиков, дождавшись идущих Send, повторный Close ничего не делает
 func (b *Broadcaster[T]) Close() {
-	for _, ch := range b.casts {
-		close(ch)
+	b.sendMu.Lock()
+	defer b.sendMu.Unlock()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return
+	}
+	b.closed = true
+	for _, sub := range b.subs {
+		close(sub.ch)
 	}
 }
 
 func (b *Broadcaster[T]) Create() platform.Broadcaster[T] {
 	return &Broadcaster[T]{
-		buf: buffer,
+		opts: b.opts,
 	}
 }
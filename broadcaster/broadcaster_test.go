@@ -0,0 +1,161 @@
+package broadcaster_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"gitlab.ozon.ru/validator/broadcaster"
+	"gitlab.ozon.ru/validator/platform"
+)
+
+func TestSlowSubscriberDoesNotStallOthers(t *testing.T) {
+	proto := broadcaster.NewBroadcaster[int](broadcaster.WithSubscriberBuffer("fast", 1))
+	b := proto.Create().(*broadcaster.Broadcaster[int])
+	slow := b.SubNamed("slow")
+	fast := b.SubNamed("fast")
+	other := b.SubNamed("other")
+
+	sent := make(chan error, 1)
+	go func() {
+		for i := 0; i < 2; i++ {
+			if err := b.Send(context.Background(), i); err != nil {
+				sent <- err
+				return
+			}
+		}
+		sent <- nil
+	}()
+
+	// slow подписан первым и молчит, но other получает значение, не дожидаясь его
+	select {
+	case v := <-other:
+		if v != 0 {
+			t.Fatalf("expected 0, got %d", v)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("other subscriber is stalled by the slow one")
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	for i := 0; i < 2; i++ {
+		if v := <-slow; v != i {
+			t.Fatalf("expected %d, got %d", i, v)
+		}
+	}
+	if v := <-other; v != 1 {
+		t.Fatalf("expected 1, got %d", v)
+	}
+	for i := 0; i < 2; i++ {
+		if v := <-fast; v != i {
+			t.Fatalf("expected %d, got %d", i, v)
+		}
+	}
+	if err := <-sent; err != nil {
+		t.Fatal(err)
+	}
+
+	stats := make(map[string]platform.SubscriberStats)
+	for _, s := range b.BlockStats() {
+		stats[s.Subscriber] = s
+	}
+	if s := stats["slow"]; s.Sends != 2 || s.Blocked == 0 || s.BlockedTime < 20*time.Millisecond || s.Buffer != 0 {
+		t.Fatalf("unexpected slow subscriber stats: %+v", s)
+	}
+	if s := stats["fast"]; s.Buffer != 1 || s.Sends != 2 {
+		t.Fatalf("unexpected fast subscriber stats: %+v", s)
+	}
+}
+
+func TestSendCancelled(t *testing.T) {
+	b := broadcaster.NewBroadcaster[int]()
+	_ = b.Sub()
+	_ = b.Sub()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := b.Send(ctx, 1); err == nil {
+		t.Fatal("expected error when nobody reads")
+	}
+}
+
+func TestReplay(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		limit    int
+		expected []int
+	}{
+		{name: "full", limit: 0, expected: []int{0, 1, 2, 3, 4}},
+		{name: "bounded", limit: 2, expected: []int{3, 4}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			b := broadcaster.NewBroadcaster[int](broadcaster.WithReplay(tc.limit)).Create()
+			early := b.Sub()
+			go func() {
+				for range early {
+				}
+			}()
+			for i := 0; i < 5; i++ {
+				if err := b.Send(context.Background(), i); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			late := b.Sub()
+			b.Close()
+			// подписка после Close тоже получает историю
+			closed := b.Sub()
+			for _, ch := range []chan int{late, closed} {
+				var got []int
+				for v := range ch {
+					got = append(got, v)
+				}
+				if len(got) != len(tc.expected) {
+					t.Fatalf("expected %v, got %v", tc.expected, got)
+				}
+				for i := range got {
+					if got[i] != tc.expected[i] {
+						t.Fatalf("expected %v, got %v", tc.expected, got)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestConcurrentSubscribers(t *testing.T) {
+	const count = 200
+	b := broadcaster.NewBroadcaster[int](broadcaster.WithReplay(0), broadcaster.WithBuffer(4)).Create()
+
+	results := make(chan []int, 10)
+	for i := 0; i < 10; i++ {
+		go func() {
+			var got []int
+			for v := range b.Sub() {
+				got = append(got, v)
+			}
+			results <- got
+		}()
+	}
+	for i := 0; i < count; i++ {
+		if err := b.Send(context.Background(), i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	b.Close()
+
+	for i := 0; i < 10; i++ {
+		got := <-results
+		if len(got) != count {
+			t.Fatalf("subscriber got %d of %d results", len(got), count)
+		}
+		for j, v := range got {
+			if v != j {
+				t.Fatalf("result %d is out of order: %d", j, v)
+			}
+		}
+	}
+	if err := b.Send(context.Background(), count); err == nil {
+		t.Fatal("expected error on send after close")
+	}
+}
@@ -0,0 +1,43 @@
+package broadcaster
+
+import (
+	"encoding/json"
+	"reflect"
+
+	"gitlab.ozon.ru/validator/platform"
+)
+
+// Codec - как результаты ложатся в Redis и поднимаются обратно
+type Codec[T any] interface {
+	Marshal(obj T) ([]byte, error)
+	Unmarshal(data []byte) (T, error)
+}
+
+// JSONCodec - обычный json, подходит для T без интерфейсов внутри
+type JSONCodec[T any] struct{}
+
+func (JSONCodec[T]) Marshal(obj T) ([]byte, error) {
+	return json.Marshal(obj)
+}
+
+func (JSONCodec[T]) Unmarshal(data []byte) (res T, err error) {
+	err = json.Unmarshal(data, &res)
+	return res, err
+}
+
+type jobResultCodec struct {
+	resType reflect.Type
+}
+
+// JobResultCodec - кодек для JobResult поверх platform.EncodeResult, Res поднимается в resType
+func JobResultCodec(resType reflect.Type) Codec[platform.JobResult] {
+	return jobResultCodec{resType: resType}
+}
+
+func (c jobResultCodec) Marshal(obj platform.JobResult) ([]byte, error) {
+	return platform.EncodeResult(obj)
+}
+
+func (c jobResultCodec) Unmarshal(data []byte) (platform.JobResult, error) {
+	return platform.DecodeResult(data, c.resType)
+}
@@ -0,0 +1,305 @@
+package broadcaster
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"gitlab.ozon.ru/platform/tracer-go/logger"
+	"gitlab.ozon.ru/validator/platform"
+)
+
+const (
+	defaultStreamPrefix = "validator:results"
+	// defaultStreamTTL - брошенный стрим, например если отправитель умер до Close, не должен жить в Redis вечно
+	defaultStreamTTL = 24 * time.Hour
+	// defaultBlock - сколько XREADGROUP ждет новых записей, потом переспрашиваем
+	defaultBlock = time.Second
+	// readCount - сколько записей забираем за раз
+	readCount = 128
+
+	dataField  = "data"
+	closeField = "close"
+)
+
+// RedisBroadcaster - броадкастер поверх Redis Streams, чтобы джобы одного пайплайна работали в разных процессах.
+// Каждый Send - отдельная запись стрима с результатом по строке, у каждого подписчика своя consumer group,
+// поэтому подписчик, пришедший позже или из другого процесса, все равно читает стрим с начала (WithReplay не нужен).
+// Close дописывает метку конца, дочитав до нее подписчик получает закрытый канал.
+// Если метки нет (отправитель умер), подписку останавливает Stop или контекст из SubContext
+type RedisBroadcaster[T any] struct {
+	client   redis.UniversalClient
+	codec    Codec[T]
+	opts     options
+	stream   string
+	consumer string
+
+	// ctx - живет, пока не позвали Stop, на нем читают все подписки этой копии
+	ctx  context.Context
+	stop context.CancelFunc
+
+	// mu - Close ждет идущие Send, чтобы метка конца была последней записью
+	mu     sync.RWMutex
+	closed bool
+}
+
+// WithStream - ключ стрима, по нему другой процесс подключается к стриму уже работающей джобы.
+// Действует только на сам NewRedisBroadcaster, копии из Create всегда берут новый ключ
+func WithStream(key string) opt {
+	return func(o *options) {
+		o.stream = key
+	}
+}
+
+// WithStreamPrefix - префикс ключей стримов, ключ копии - prefix:uuid
+func WithStreamPrefix(prefix string) opt {
+	return func(o *options) {
+		o.streamPrefix = prefix
+	}
+}
+
+// WithStreamMaxLen - примерный предел длины стрима (XADD MAXLEN ~).
+// Срезаются и записи, которые медленный подписчик еще не прочитал, так что ставить с запасом
+func WithStreamMaxLen(maxLen int64) opt {
+	return func(o *options) {
+		o.maxLen = maxLen
+	}
+}
+
+// WithStreamTTL - сколько стрим живет после последней записи, чтобы законченные и брошенные без Close пайплайны
+// не копились в Redis. По умолчанию сутки, 0 - без TTL
+func WithStreamTTL(ttl time.Duration) opt {
+	return func(o *options) {
+		o.ttl = ttl
+	}
+}
+
+// WithReadBlock - сколько подписчик за раз ждет новых записей в XREADGROUP
+func WithReadBlock(block time.Duration) opt {
+	return func(o *options) {
+		o.block = block
+	}
+}
+
+// NewRedisBroadcaster - прототип броадкастера на client, результаты пишутся через codec.
+// Буферы каналов подписчиков задаются как у Broadcaster: WithBuffer и WithSubscriberBuffer
+func NewRedisBroadcaster[T any](client redis.UniversalClient, codec Codec[T], opts ...opt) *RedisBroadcaster[T] {
+	o := options{buf: buffer, streamPrefix: defaultStreamPrefix, block: defaultBlock, ttl: defaultStreamTTL}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return newRedisBroadcaster(client, codec, o, o.stream)
+}
+
+func newRedisBroadcaster[T any](client redis.UniversalClient, codec Codec[T], o options, stream string) *RedisBroadcaster[T] {
+	if stream == "" {
+		stream = o.streamPrefix + ":" + uuid.NewString()
+	}
+	ctx, stop := context.WithCancel(context.Background())
+	return &RedisBroadcaster[T]{
+		client:   client,
+		codec:    codec,
+		opts:     o,
+		stream:   stream,
+		consumer: uuid.NewString(),
+		ctx:      ctx,
+		stop:     stop,
+	}
+}
+
+// Stream - ключ стрима, его передаем процессу, который будет читать результаты
+func (b *RedisBroadcaster[T]) Stream() string {
+	return b.stream
+}
+
+func (b *RedisBroadcaster[T]) Sub() chan T {
+	return b.SubNamed("")
+}
+
+// SubNamed - подписка consumer group с именем name, пустое имя - своя анонимная группа.
+// Подписки с одним именем, в том числе из разных процессов, делят записи группы между собой.
+// Если группу создать не вышло, канал сразу закрыт и подписчик получит ErrFatal на Recv
+func (b *RedisBroadcaster[T]) SubNamed(name string) chan T {
+	return b.SubContext(context.Background(), name)
+}
+
+// SubContext - SubNamed, которую подписчик может остановить через ctx, например если перестал читать канал.
+// После остановки канал закрывается, а анонимная группа удаляется из Redis
+func (b *RedisBroadcaster[T]) SubContext(ctx context.Context, name string) chan T {
+	size := b.opts.buf
+	if subBuf, exists := b.opts.subBuf[name]; exists {
+		size = subBuf
+	}
+	ch := make(chan T, size)
+
+	group := name
+	if group == "" {
+		group = "sub:" + uuid.NewString()
+	}
+	err := b.client.XGroupCreateMkStream(ctx, b.stream, group, "0").Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		logger.Errorf(ctx, "failed to create consumer group %s on %s: %v", group, b.stream, err)
+		close(ch)
+		return ch
+	}
+	b.expireCreated(ctx)
+
+	// подписку останавливает и подписчик, и Stop всей копии
+	ctx, cancel := context.WithCancel(ctx)
+	go func() {
+		select {
+		case <-b.ctx.Done():
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	go func() {
+		defer cancel()
+		b.pump(ctx, group, ch)
+		if name == "" {
+			b.destroy(group)
+		}
+	}()
+	return ch
+}
+
+// Stop - останавливает все подписки этой копии, даже если метки конца в стриме так и не будет.
+// Пайплайн зовет его, когда все его джобы закончились
+func (b *RedisBroadcaster[T]) Stop() {
+	b.stop()
+}
+
+// pump - перекладывает записи группы в канал подписчика, пока не встретит метку конца или не отменят ctx.
+// Запись подтверждаем, только когда подписчик забрал ее в канал
+func (b *RedisBroadcaster[T]) pump(ctx context.Context, group string, ch chan T) {
+	defer close(ch)
+	for {
+		streams, err := b.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+			Group:    group,
+			Consumer: b.consumer,
+			Streams:  []string{b.stream, ">"},
+			Count:    readCount,
+			Block:    b.opts.block,
+		}).Result()
+		if ctx.Err() != nil {
+			return
+		}
+		if err == redis.Nil {
+			continue
+		}
+		if err != nil {
+			logger.Errorf(ctx, "failed to read %s as %s: %v", b.stream, group, err)
+			return
+		}
+
+		for _, stream := range streams {
+			for _, msg := range stream.Messages {
+				if _, done := msg.Values[closeField]; done {
+					b.ack(group, msg.ID)
+					return
+				}
+				data, _ := msg.Values[dataField].(string)
+				obj, err := b.codec.Unmarshal([]byte(data))
+				if err != nil {
+					logger.Errorf(ctx, "failed to decode %s from %s: %v", msg.ID, b.stream, err)
+					return
+				}
+				select {
+				case ch <- obj:
+				case <-ctx.Done():
+					return
+				}
+				b.ack(group, msg.ID)
+			}
+		}
+	}
+}
+
+// destroy - анонимную группу кроме этой подписки никто не прочитает, не оставляем ее в стриме
+func (b *RedisBroadcaster[T]) destroy(group string) {
+	ctx := context.Background()
+	if err := b.client.XGroupDestroy(ctx, b.stream, group).Err(); err != nil {
+		logger.Errorf(ctx, "failed to destroy consumer group %s on %s: %v", group, b.stream, err)
+	}
+}
+
+// ack - подписчик запись уже забрал, так что подтверждаем даже если подписку сейчас останавливают
+func (b *RedisBroadcaster[T]) ack(group, id string) {
+	ctx := context.Background()
+	if err := b.client.XAck(ctx, b.stream, group, id).Err(); err != nil {
+		logger.Errorf(ctx, "failed to ack %s on %s: %v", id, b.stream, err)
+	}
+}
+
+// Send - дописывает obj в стрим, подписчиков не ждет
+func (b *RedisBroadcaster[T]) Send(ctx context.Context, obj T) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if b.closed {
+		return errors.Wrap(platform.ErrFatal, "send on closed broadcaster")
+	}
+
+	data, err := b.codec.Marshal(obj)
+	if err != nil {
+		return errors.Wrapf(platform.ErrFatal, "failed to encode result: %v", err)
+	}
+	return b.add(ctx, map[string]interface{}{dataField: data})
+}
+
+// add - дописывает запись и продлевает TTL стрима, одним походом в Redis
+func (b *RedisBroadcaster[T]) add(ctx context.Context, values map[string]interface{}) error {
+	args := &redis.XAddArgs{Stream: b.stream, Values: values}
+	if b.opts.maxLen > 0 {
+		args.MaxLen, args.Approx = b.opts.maxLen, true
+	}
+	_, err := b.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.XAdd(ctx, args)
+		if b.opts.ttl > 0 {
+			pipe.Expire(ctx, b.stream, b.opts.ttl)
+		}
+		return nil
+	})
+	if err != nil {
+		return errors.Wrapf(platform.ErrFatal, "failed to add to %s: %v", b.stream, err)
+	}
+	return nil
+}
+
+// expireCreated - подписчик мог прийти раньше отправителя и создать стрим сам, без TTL.
+// TTL, который уже поставил отправитель, не трогаем
+func (b *RedisBroadcaster[T]) expireCreated(ctx context.Context) {
+	if b.opts.ttl <= 0 {
+		return
+	}
+	ttl, err := b.client.TTL(ctx, b.stream).Result()
+	if err == nil && ttl < 0 {
+		err = b.client.Expire(ctx, b.stream, b.opts.ttl).Err()
+	}
+	if err != nil {
+		logger.Errorf(ctx, "failed to set ttl on %s: %v", b.stream, err)
+	}
+}
+
+// Close - дописывает метку конца, повторный Close ничего не делает
+func (b *RedisBroadcaster[T]) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return
+	}
+	b.closed = true
+
+	ctx := context.Background()
+	if err := b.add(ctx, map[string]interface{}{closeField: 1}); err != nil {
+		logger.Errorf(ctx, "failed to close %s: %v", b.stream, err)
+	}
+}
+
+// Create - копия на новом стриме: у каждой джобы каждого пайплайна свой
+func (b *RedisBroadcaster[T]) Create() platform.Broadcaster[T] {
+	return newRedisBroadcaster(b.client, b.codec, b.opts, "")
+}
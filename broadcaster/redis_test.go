@@ -0,0 +1,170 @@
+package broadcaster_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/pkg/errors"
+	"gitlab.ozon.ru/validator/broadcaster"
+	"gitlab.ozon.ru/validator/platform"
+)
+
+func newRedisClient(t *testing.T, server *miniredis.Miniredis) *redis.Client {
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func recvAll(t *testing.T, ch chan platform.JobResult) (res []platform.JobResult) {
+	t.Helper()
+	for {
+		select {
+		case r, ok := <-ch:
+			if !ok {
+				return res
+			}
+			res = append(res, r)
+		case <-time.After(2 * time.Second):
+			t.Fatalf("channel was not closed, got %d results", len(res))
+		}
+	}
+}
+
+func checkResults(t *testing.T, got []platform.JobResult) {
+	t.Helper()
+	if len(got) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(got))
+	}
+	for i, r := range got[:2] {
+		if r.Err != nil || r.Row != i || r.Span != 1 || r.Res != int64(i*10) {
+			t.Fatalf("unexpected result %d: %+v", i, r)
+		}
+	}
+	if last := got[2]; !errors.Is(last.Err, platform.ErrSkipped) || last.Row != 2 {
+		t.Fatalf("expected skipped row 2, got %+v", last)
+	}
+}
+
+func sendResults(t *testing.T, b platform.Broadcaster[platform.JobResult]) {
+	t.Helper()
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		if err := b.Send(ctx, platform.JobResult{Res: int64(i * 10), Row: i, Span: 1}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := b.Send(ctx, platform.JobResult{Err: platform.ErrSkipped, Row: 2, Span: 1}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRedisBroadcaster(t *testing.T) {
+	server := miniredis.RunT(t)
+	proto := broadcaster.NewRedisBroadcaster(newRedisClient(t, server),
+		broadcaster.JobResultCodec(platform.TypeOf[int64]()), broadcaster.WithReadBlock(50*time.Millisecond))
+	b := proto.Create().(*broadcaster.RedisBroadcaster[platform.JobResult])
+	if b.Stream() == proto.Stream() {
+		t.Fatal("copy must get its own stream")
+	}
+
+	first := b.SubNamed("first")
+	second := b.SubNamed("second")
+	sendResults(t, b)
+	b.Close()
+	b.Close()
+
+	checkResults(t, recvAll(t, first))
+	checkResults(t, recvAll(t, second))
+	if err := b.Send(context.Background(), platform.JobResult{}); !errors.Is(err, platform.ErrFatal) {
+		t.Fatalf("expected fatal error on send after close, got %v", err)
+	}
+}
+
+// подписчик из другого процесса приходит к уже закрытому стриму и читает его с начала
+func TestRedisSubscriberInAnotherProcess(t *testing.T) {
+	server := miniredis.RunT(t)
+	codec := broadcaster.JobResultCodec(platform.TypeOf[int64]())
+	sender := broadcaster.NewRedisBroadcaster(newRedisClient(t, server), codec,
+		broadcaster.WithStreamTTL(time.Minute)).Create().(*broadcaster.RedisBroadcaster[platform.JobResult])
+	sendResults(t, sender)
+	sender.Close()
+
+	reader := broadcaster.NewRedisBroadcaster(newRedisClient(t, server), codec,
+		broadcaster.WithStream(sender.Stream()), broadcaster.WithReadBlock(50*time.Millisecond))
+	checkResults(t, recvAll(t, reader.SubNamed("reader")))
+	if ttl := server.TTL(sender.Stream()); ttl <= 0 {
+		t.Fatalf("expected stream ttl, got %v", ttl)
+	}
+}
+
+func TestJobResultCodec(t *testing.T) {
+	codec := broadcaster.JobResultCodec(platform.TypeOf[[]string]())
+	data, err := codec.Marshal(platform.JobResult{
+		Res: []string{"a", "b"},
+		Err: errors.Wrap(platform.ErrFatal, "dependency failed"),
+		Row: 4, Span: 2,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := codec.Unmarshal(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, ok := res.Res.([]string); !ok || len(got) != 2 || got[1] != "b" || res.Row != 4 || res.Span != 2 {
+		t.Fatalf("unexpected result %+v", res)
+	}
+	if !errors.Is(res.Err, platform.ErrFatal) || res.Err.Error() != "dependency failed: fatal job error" {
+		t.Fatalf("unexpected error %v", res.Err)
+	}
+}
+
+// отправитель так и не закрыл стрим: подписку останавливает подписчик, анонимная группа удаляется
+func TestRedisSubscriptionStops(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := newRedisClient(t, server)
+	b := broadcaster.NewRedisBroadcaster(client, broadcaster.JobResultCodec(platform.TypeOf[int64]()),
+		broadcaster.WithReadBlock(50*time.Millisecond)).Create().(*broadcaster.RedisBroadcaster[platform.JobResult])
+	sendResults(t, b)
+	if ttl := server.TTL(b.Stream()); ttl <= 0 {
+		t.Fatalf("stream must expire even without close, got ttl %v", ttl)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := b.SubContext(ctx, "")
+	if res := <-ch; res.Row != 0 {
+		t.Fatalf("unexpected first result %+v", res)
+	}
+	// остальные результаты подписчик не читает, pump не должен на них зависнуть
+	cancel()
+	recvAll(t, ch)
+
+	for deadline := time.Now().Add(2 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+		groups, err := client.Do(context.Background(), "XINFO", "GROUPS", b.Stream()).Slice()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(groups) == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("anonymous groups are left: %+v", groups)
+		}
+	}
+}
+
+// Stop останавливает все подписки копии, даже именованные и без метки конца
+func TestRedisBroadcasterStop(t *testing.T) {
+	server := miniredis.RunT(t)
+	b := broadcaster.NewRedisBroadcaster(newRedisClient(t, server), broadcaster.JobResultCodec(platform.TypeOf[int64]()),
+		broadcaster.WithReadBlock(50*time.Millisecond)).Create().(*broadcaster.RedisBroadcaster[platform.JobResult])
+	first, second := b.SubNamed("first"), b.SubNamed("second")
+	sendResults(t, b)
+
+	b.Stop()
+	recvAll(t, first)
+	recvAll(t, second)
+}
@@ -0,0 +1,76 @@
+package cache_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"gitlab.ozon.ru/validator/cache"
+	"gitlab.ozon.ru/validator/platform"
+)
+
+func checkStore(t *testing.T, store platform.ResultStore) {
+	t.Helper()
+	ctx := context.Background()
+	if _, found, err := store.Get(ctx, "abcdef"); err != nil || found {
+		t.Fatalf("unexpected hit on empty store: %v", err)
+	}
+	for _, val := range []string{"first", "second"} {
+		if err := store.Set(ctx, "abcdef", []byte(val)); err != nil {
+			t.Fatal(err)
+		}
+		got, found, err := store.Get(ctx, "abcdef")
+		if err != nil || !found || string(got) != val {
+			t.Fatalf("expected %s, got %q, found %t: %v", val, got, found, err)
+		}
+	}
+}
+
+func TestLRU(t *testing.T) {
+	checkStore(t, cache.NewLRU(10))
+
+	ctx := context.Background()
+	lru := cache.NewLRU(2)
+	_ = lru.Set(ctx, "a", []byte("a"))
+	_ = lru.Set(ctx, "b", []byte("b"))
+	// a прочитали, поэтому вытесняется b
+	_, _, _ = lru.Get(ctx, "a")
+	_ = lru.Set(ctx, "c", []byte("c"))
+	if _, found, _ := lru.Get(ctx, "b"); found || lru.Len() != 2 {
+		t.Fatalf("least recently used key must be evicted, %d keys left", lru.Len())
+	}
+	if _, found, _ := lru.Get(ctx, "a"); !found {
+		t.Fatal("recently used key is evicted")
+	}
+}
+
+func TestDisk(t *testing.T) {
+	dir := t.TempDir()
+	store, err := cache.NewDisk(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkStore(t, store)
+
+	// новый процесс видит то, что записал прошлый
+	reopened, err := cache.NewDisk(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, found, err := reopened.Get(context.Background(), "abcdef"); err != nil || !found || string(got) != "second" {
+		t.Fatalf("value is lost after reopening: %q, %v", got, err)
+	}
+}
+
+func TestRedis(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer client.Close()
+
+	checkStore(t, cache.NewRedis(client, cache.WithPrefix("test:"), cache.WithTTL(time.Hour)))
+	if ttl := server.TTL("test:abcdef"); ttl != time.Hour {
+		t.Fatalf("expected ttl of an hour, got %v", ttl)
+	}
+}
@@ -0,0 +1,64 @@
+package cache
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+// Disk - кеш в папке, файл на ключ, переживает перезапуски CLI.
+// Ключи - hex хеши, раскладываем по подпапкам из первых двух символов, чтобы не держать все в одной
+type Disk struct {
+	dir string
+}
+
+func NewDisk(dir string) (*Disk, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, errors.Wrap(err, "failed to create cache dir")
+	}
+	return &Disk{dir: dir}, nil
+}
+
+func (c *Disk) path(key string) string {
+	if len(key) > 2 {
+		return filepath.Join(c.dir, key[:2], key)
+	}
+	return filepath.Join(c.dir, key)
+}
+
+func (c *Disk) Get(_ context.Context, key string) ([]byte, bool, error) {
+	data, err := os.ReadFile(c.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, errors.Wrapf(err, "failed to read %s", key)
+	}
+	return data, true, nil
+}
+
+// Set - пишем во временный файл и переименовываем, чтобы параллельный Get не прочитал половину записи
+func (c *Disk) Set(_ context.Context, key string, val []byte) error {
+	path := c.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return errors.Wrapf(err, "failed to write %s", key)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), key+".*.tmp")
+	if err != nil {
+		return errors.Wrapf(err, "failed to write %s", key)
+	}
+	_, err = tmp.Write(val)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return errors.Wrapf(err, "failed to write %s", key)
+	}
+	return nil
+}
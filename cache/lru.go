@@ -0,0 +1,64 @@
+// Package cache - хранилища для platform.ResultStore: в памяти, на диске и в Redis
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+)
+
+// LRU - кеш в памяти на size записей, вытесняется то, что дольше всех не трогали
+type LRU struct {
+	mu    sync.Mutex
+	size  int
+	items map[string]*list.Element
+	order *list.List
+}
+
+type lruItem struct {
+	key string
+	val []byte
+}
+
+func NewLRU(size int) *LRU {
+	return &LRU{
+		size:  size,
+		items: make(map[string]*list.Element, size),
+		order: list.New(),
+	}
+}
+
+func (c *LRU) Get(_ context.Context, key string) ([]byte, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, exists := c.items[key]
+	if !exists {
+		return nil, false, nil
+	}
+	c.order.MoveToFront(elem)
+	return elem.Value.(*lruItem).val, true, nil
+}
+
+func (c *LRU) Set(_ context.Context, key string, val []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, exists := c.items[key]; exists {
+		elem.Value.(*lruItem).val = val
+		c.order.MoveToFront(elem)
+		return nil
+	}
+	c.items[key] = c.order.PushFront(&lruItem{key: key, val: val})
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*lruItem).key)
+	}
+	return nil
+}
+
+// Len - сколько записей сейчас в кеше
+func (c *LRU) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
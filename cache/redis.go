@@ -0,0 +1,64 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/pkg/errors"
+)
+
+const defaultPrefix = "validator:cache:"
+
+// Redis - кеш в Redis, общий для всех инстансов сервиса
+type Redis struct {
+	client redis.UniversalClient
+	opts   options
+}
+
+type options struct {
+	prefix string
+	ttl    time.Duration
+}
+
+type opt func(o *options)
+
+// WithPrefix - префикс ключей кеша
+func WithPrefix(prefix string) opt {
+	return func(o *options) {
+		o.prefix = prefix
+	}
+}
+
+// WithTTL - сколько живет запись, 0 - пока Redis сам не вытеснит
+func WithTTL(ttl time.Duration) opt {
+	return func(o *options) {
+		o.ttl = ttl
+	}
+}
+
+func NewRedis(client redis.UniversalClient, opts ...opt) *Redis {
+	res := &Redis{client: client, opts: options{prefix: defaultPrefix}}
+	for _, opt := range opts {
+		opt(&res.opts)
+	}
+	return res
+}
+
+func (c *Redis) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	data, err := c.client.Get(ctx, c.opts.prefix+key).Bytes()
+	if err == redis.Nil {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, errors.Wrapf(err, "failed to get %s", key)
+	}
+	return data, true, nil
+}
+
+func (c *Redis) Set(ctx context.Context, key string, val []byte) error {
+	if err := c.client.Set(ctx, c.opts.prefix+key, val, c.opts.ttl).Err(); err != nil {
+		return errors.Wrapf(err, "failed to set %s", key)
+	}
+	return nil
+}
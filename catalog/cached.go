@@ -0,0 +1,84 @@
+package catalog
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Lookuper - любой каталог СКУ, его оборачивает Cached
+type Lookuper interface {
+	Lookup(ctx context.Context, skus []int64) (map[int64]struct{}, error)
+}
+
+// Store - куда Cached кладет найденные СКУ, подходит platform.ResultStore из пакета cache
+type Store interface {
+	Get(ctx context.Context, key string) (val []byte, found bool, err error)
+	Set(ctx context.Context, key string, val []byte) error
+}
+
+// Cached - каталог, который помнит найденные СКУ и спрашивает inner только про остальные.
+// SkuChecker зависит от другой джобы, поэтому кеш строк платформы его не берет, а так повторная
+// проверка исправленного файла не ходит в каталог за теми же СКУ.
+// Отсутствующие СКУ не запоминаются: их могут завести в каталог, и исправленный файл должен это увидеть.
+// Найденные живут WithCacheTTL: СКУ могут и убрать из каталога, а дисковый кеш сам ничего не вытесняет
+type Cached struct {
+	inner Lookuper
+	store Store
+	// namespace - чей это кеш, например адрес каталога, чтобы разные каталоги не смешивались
+	namespace string
+	ttl       time.Duration
+}
+
+func NewCached(inner Lookuper, store Store, namespace string, opts ...opt) *Cached {
+	return &Cached{inner: inner, store: store, namespace: namespace, ttl: newOptions(opts).cacheTTL}
+}
+
+// Lookup - известные СКУ берет из store, про остальные спрашивает inner одним Lookup.
+// Хранилище не должно ломать проверку: на его ошибках СКУ просто спрашиваются у inner
+func (c *Cached) Lookup(ctx context.Context, skus []int64) (map[int64]struct{}, error) {
+	res := make(map[int64]struct{}, len(skus))
+	misses := make([]int64, 0, len(skus))
+	now := time.Now()
+	for _, sku := range skus {
+		if val, found, err := c.store.Get(ctx, c.key(sku)); err == nil && found && c.fresh(val, now) {
+			res[sku] = struct{}{}
+			continue
+		}
+		misses = append(misses, sku)
+	}
+	if len(misses) == 0 {
+		return res, nil
+	}
+
+	existing, err := c.inner.Lookup(ctx, misses)
+	if err != nil {
+		return nil, err
+	}
+	// в значении время, когда СКУ нашелся в каталоге
+	found := strconv.AppendInt(nil, now.UnixNano(), 10)
+	for sku := range existing {
+		res[sku] = struct{}{}
+		_ = c.store.Set(ctx, c.key(sku), found)
+	}
+	return res, nil
+}
+
+// fresh - СКУ нашелся в каталоге меньше ttl назад, записи без времени считаются устаревшими
+func (c *Cached) fresh(val []byte, now time.Time) bool {
+	foundAt, err := strconv.ParseInt(string(val), 10, 64)
+	if err != nil {
+		return false
+	}
+	return now.Sub(time.Unix(0, foundAt)) < c.ttl
+}
+
+// key - sha256, чтобы ключ годился и для имени файла в cache.Disk
+func (c *Cached) key(sku int64) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "sku-catalog\x00%s\x00%d", c.namespace, sku)
+	return hex.EncodeToString(h.Sum(nil))
+}
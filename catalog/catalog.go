@@ -0,0 +1,153 @@
+// Package catalog - откуда SkuChecker узнает, какие СКУ существуют: выгрузка в файле, set в Redis или http каталог
+package catalog
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultBatchSize = 500
+	defaultRetries   = 3
+	defaultBackoff   = 200 * time.Millisecond
+	defaultCacheTTL  = time.Hour
+)
+
+// Set - каталог в памяти, например из выгрузки
+type Set map[int64]struct{}
+
+// Lookup - какие из skus есть в каталоге
+func (s Set) Lookup(_ context.Context, skus []int64) (map[int64]struct{}, error) {
+	res := make(map[int64]struct{}, len(skus))
+	for _, sku := range skus {
+		if _, exists := s[sku]; exists {
+			res[sku] = struct{}{}
+		}
+	}
+	return res, nil
+}
+
+// LoadFile - выгрузка каталога: json массив СКУ (числа или строки) или csv/tsv с СКУ в первой колонке,
+// первая строка csv может быть заголовком
+func LoadFile(path string) (Set, error) {
+	//nolint:gosec
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read sku catalog")
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return parseJSON(data)
+	case ".tsv", ".tab":
+		return parseCSV(data, '\t')
+	}
+	return parseCSV(data, ',')
+}
+
+func parseJSON(data []byte) (Set, error) {
+	var skus []json.Number
+	if err := json.Unmarshal(data, &skus); err != nil {
+		return nil, errors.Wrap(err, "sku catalog must be a json array of skus")
+	}
+	res := make(Set, len(skus))
+	for i, raw := range skus {
+		sku, err := raw.Int64()
+		if err != nil {
+			return nil, errors.Errorf("sku catalog item %d: %q is not a sku", i, raw)
+		}
+		res[sku] = struct{}{}
+	}
+	return res, nil
+}
+
+func parseCSV(data []byte, comma rune) (Set, error) {
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))))
+	reader.Comma = comma
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read sku catalog")
+	}
+
+	res := make(Set, len(records))
+	for i, record := range records {
+		if len(record) == 0 || strings.TrimSpace(record[0]) == "" {
+			continue
+		}
+		sku, err := strconv.ParseInt(strings.TrimSpace(record[0]), 10, 64)
+		if err != nil {
+			if i == 0 {
+				// заголовок
+				continue
+			}
+			return nil, errors.Errorf("sku catalog line %d: %q is not a sku", i+1, record[0])
+		}
+		res[sku] = struct{}{}
+	}
+	return res, nil
+}
+
+type options struct {
+	batchSize int
+	retries   int
+	backoff   time.Duration
+	client    httpDoer
+	cacheTTL  time.Duration
+}
+
+type opt func(o *options)
+
+// WithBatchSize - сколько СКУ спрашиваем за один поход
+func WithBatchSize(size int) opt {
+	return func(o *options) {
+		o.batchSize = size
+	}
+}
+
+// WithRetries - сколько раз повторяем неудачный поход в http каталог, пауза между попытками растет вдвое от backoff
+func WithRetries(retries int, backoff time.Duration) opt {
+	return func(o *options) {
+		o.retries = retries
+		o.backoff = backoff
+	}
+}
+
+// WithCacheTTL - сколько Cached верит найденному СКУ, потом снова спрашивает каталог
+func WithCacheTTL(ttl time.Duration) opt {
+	return func(o *options) {
+		o.cacheTTL = ttl
+	}
+}
+
+func newOptions(opts []opt) options {
+	res := options{batchSize: defaultBatchSize, retries: defaultRetries, backoff: defaultBackoff, cacheTTL: defaultCacheTTL}
+	for _, opt := range opts {
+		opt(&res)
+	}
+	if res.batchSize <= 0 {
+		res.batchSize = defaultBatchSize
+	}
+	return res
+}
+
+// batches - skus пачками по size
+func batches(skus []int64, size int) [][]int64 {
+	res := make([][]int64, 0, len(skus)/size+1)
+	for from := 0; from < len(skus); from += size {
+		to := from + size
+		if to > len(skus) {
+			to = len(skus)
+		}
+		res = append(res, skus[from:to])
+	}
+	return res
+}
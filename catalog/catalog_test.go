@@ -0,0 +1,193 @@
+package catalog_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"gitlab.ozon.ru/validator/cache"
+	"gitlab.ozon.ru/validator/catalog"
+)
+
+type lookuper interface {
+	Lookup(ctx context.Context, skus []int64) (map[int64]struct{}, error)
+}
+
+// checkLookup - в каталоге 1, 3 и 5
+func checkLookup(t *testing.T, c lookuper) {
+	t.Helper()
+	got, err := c.Lookup(context.Background(), []int64{1, 2, 3, 4, 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 3 {
+		t.Fatalf("expected 3 existing skus, got %v", got)
+	}
+	for _, sku := range []int64{1, 3, 5} {
+		if _, exists := got[sku]; !exists {
+			t.Fatalf("sku %d is not found: %v", sku, got)
+		}
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"skus.csv":  "SKU,Название\n1,первый\n3,третий\n5,пятый\n",
+		"skus.tsv":  "1\n3\n5\n",
+		"skus.json": `[1, "3", 5]`,
+	} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		set, err := catalog.LoadFile(path)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		checkLookup(t, set)
+	}
+
+	path := filepath.Join(dir, "broken.csv")
+	_ = os.WriteFile(path, []byte("1\nне ску\n"), 0o600)
+	if _, err := catalog.LoadFile(path); err == nil {
+		t.Fatal("expected an error for a line without sku")
+	}
+}
+
+func TestRedis(t *testing.T) {
+	server := miniredis.RunT(t)
+	if _, err := server.SAdd("skus", "1", "3", "5"); err != nil {
+		t.Fatal(err)
+	}
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer client.Close()
+
+	checkLookup(t, catalog.NewRedis(client, "skus", catalog.WithBatchSize(2)))
+}
+
+func newCatalogServer(t *testing.T, failures int32, requests *int32) *httptest.Server {
+	existing := map[int64]bool{1: true, 3: true, 5: true}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(requests, 1) <= failures {
+			http.Error(w, "try later", http.StatusServiceUnavailable)
+			return
+		}
+		var req struct {
+			Skus []int64 `json:"skus"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || len(req.Skus) > 2 {
+			http.Error(w, "bad batch", http.StatusBadRequest)
+			return
+		}
+		resp := struct {
+			Existing []int64 `json:"existing"`
+		}{Existing: []int64{}}
+		for _, sku := range req.Skus {
+			if existing[sku] {
+				resp.Existing = append(resp.Existing, sku)
+			}
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestHTTPBatches(t *testing.T) {
+	var requests int32
+	srv := newCatalogServer(t, 0, &requests)
+	checkLookup(t, catalog.NewHTTP(srv.URL, catalog.WithBatchSize(2)))
+	if requests != 3 {
+		t.Fatalf("5 skus in batches of 2 must take 3 requests, got %d", requests)
+	}
+}
+
+func TestHTTPRetries(t *testing.T) {
+	var requests int32
+	srv := newCatalogServer(t, 2, &requests)
+	checkLookup(t, catalog.NewHTTP(srv.URL, catalog.WithBatchSize(2), catalog.WithRetries(2, time.Millisecond)))
+
+	var failing int32
+	srv = newCatalogServer(t, 2, &failing)
+	_, err := catalog.NewHTTP(srv.URL, catalog.WithRetries(1, time.Millisecond)).Lookup(context.Background(), []int64{1})
+	if err == nil || failing != 2 {
+		t.Fatalf("expected an error after 2 attempts, got %d attempts: %v", failing, err)
+	}
+}
+
+func TestHTTPDoesNotRetryBadRequest(t *testing.T) {
+	var requests int32
+	srv := newCatalogServer(t, 0, &requests)
+	// пачка больше двух СКУ сервером не принимается, повторять такое бесполезно
+	_, err := catalog.NewHTTP(srv.URL, catalog.WithRetries(3, time.Millisecond)).Lookup(context.Background(), []int64{1, 2, 3})
+	if err == nil || requests != 1 {
+		t.Fatalf("expected a single failed attempt, got %d: %v", requests, err)
+	}
+}
+
+// countingCatalog - каталог 1, 3 и 5, запоминает, о каких СКУ его спрашивали
+type countingCatalog struct {
+	asked [][]int64
+	err   error
+}
+
+func (c *countingCatalog) Lookup(ctx context.Context, skus []int64) (map[int64]struct{}, error) {
+	c.asked = append(c.asked, append([]int64(nil), skus...))
+	if c.err != nil {
+		return nil, c.err
+	}
+	return catalog.Set{1: {}, 3: {}, 5: {}}.Lookup(ctx, skus)
+}
+
+func TestCached(t *testing.T) {
+	inner := &countingCatalog{}
+	store := cache.NewLRU(100)
+	cached := catalog.NewCached(inner, store, "catalog")
+	checkLookup(t, cached)
+
+	// найденные СКУ берутся из кеша, отсутствующие спрашиваются снова
+	checkLookup(t, cached)
+	if len(inner.asked) != 2 || !reflect.DeepEqual(inner.asked[1], []int64{2, 4}) {
+		t.Fatalf("only missing skus must be asked again, asked %v", inner.asked)
+	}
+	got, err := cached.Lookup(context.Background(), []int64{1, 5})
+	if err != nil || len(got) != 2 || len(inner.asked) != 2 {
+		t.Fatalf("known skus must not reach the catalog, got %v, asked %v: %v", got, inner.asked, err)
+	}
+
+	// у другого каталога свои СКУ
+	other := &countingCatalog{}
+	checkLookup(t, catalog.NewCached(other, store, "other"))
+	if len(other.asked) != 1 || len(other.asked[0]) != 5 {
+		t.Fatalf("another catalog must not reuse the cache, asked %v", other.asked)
+	}
+
+	failing := &countingCatalog{err: errors.New("catalog is down")}
+	if _, err = catalog.NewCached(failing, store, "failing").Lookup(context.Background(), []int64{1}); err == nil {
+		t.Fatal("catalog error is lost")
+	}
+}
+
+func TestCachedExpires(t *testing.T) {
+	inner := &countingCatalog{}
+	cached := catalog.NewCached(inner, cache.NewLRU(100), "catalog", catalog.WithCacheTTL(time.Millisecond))
+	checkLookup(t, cached)
+
+	// СКУ могли убрать из каталога, после ttl найденные спрашиваются снова
+	time.Sleep(5 * time.Millisecond)
+	checkLookup(t, cached)
+	if len(inner.asked) != 2 || len(inner.asked[1]) != 5 {
+		t.Fatalf("expired skus must be asked again, asked %v", inner.asked)
+	}
+}
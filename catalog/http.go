@@ -0,0 +1,111 @@
+package catalog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const defaultHTTPTimeout = 10 * time.Second
+
+type httpDoer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// WithHTTPClient - свой http клиент, например с авторизацией
+func WithHTTPClient(client httpDoer) opt {
+	return func(o *options) {
+		o.client = client
+	}
+}
+
+// HTTP - клиент каталога: POST url с {"skus": [...]} отвечает {"existing": [...]}
+type HTTP struct {
+	url  string
+	opts options
+}
+
+type lookupRequest struct {
+	Skus []int64 `json:"skus"`
+}
+
+type lookupResponse struct {
+	Existing []int64 `json:"existing"`
+}
+
+func NewHTTP(url string, opts ...opt) *HTTP {
+	o := newOptions(opts)
+	if o.client == nil {
+		o.client = &http.Client{Timeout: defaultHTTPTimeout}
+	}
+	return &HTTP{url: url, opts: o}
+}
+
+// Lookup - спрашивает каталог пачками, неудачную пачку повторяет с растущей паузой
+func (c *HTTP) Lookup(ctx context.Context, skus []int64) (map[int64]struct{}, error) {
+	res := make(map[int64]struct{}, len(skus))
+	for _, batch := range batches(skus, c.opts.batchSize) {
+		existing, err := c.lookupWithRetries(ctx, batch)
+		if err != nil {
+			return nil, err
+		}
+		for _, sku := range existing {
+			res[sku] = struct{}{}
+		}
+	}
+	return res, nil
+}
+
+func (c *HTTP) lookupWithRetries(ctx context.Context, batch []int64) ([]int64, error) {
+	backoff := c.opts.backoff
+	for attempt := 0; ; attempt++ {
+		existing, retryable, err := c.lookup(ctx, batch)
+		if err == nil {
+			return existing, nil
+		}
+		if !retryable || attempt >= c.opts.retries {
+			return nil, errors.Wrapf(err, "failed to look up %d skus after %d attempts", len(batch), attempt+1)
+		}
+		select {
+		case <-ctx.Done():
+			return nil, errors.Wrap(err, ctx.Err().Error())
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// lookup - один поход за пачкой, retryable - есть смысл повторить: сеть, 429 и 5xx
+func (c *HTTP) lookup(ctx context.Context, batch []int64) (existing []int64, retryable bool, err error) {
+	body, err := json.Marshal(lookupRequest{Skus: batch})
+	if err != nil {
+		return nil, false, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.opts.client.Do(req)
+	if err != nil {
+		return nil, ctx.Err() == nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		retryable = resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError
+		return nil, retryable, errors.Errorf("catalog responded %s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+
+	var lookupResp lookupResponse
+	if err = json.NewDecoder(resp.Body).Decode(&lookupResp); err != nil {
+		return nil, false, errors.Wrap(err, "failed to decode catalog response")
+	}
+	return lookupResp.Existing, false, nil
+}
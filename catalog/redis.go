@@ -0,0 +1,40 @@
+package catalog
+
+import (
+	"context"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/pkg/errors"
+)
+
+// Redis - каталог в redis set, существующие СКУ - его члены
+type Redis struct {
+	client redis.UniversalClient
+	key    string
+	opts   options
+}
+
+func NewRedis(client redis.UniversalClient, key string, opts ...opt) *Redis {
+	return &Redis{client: client, key: key, opts: newOptions(opts)}
+}
+
+// Lookup - SISMEMBER пачками через pipeline, один поход в Redis на пачку
+func (c *Redis) Lookup(ctx context.Context, skus []int64) (map[int64]struct{}, error) {
+	res := make(map[int64]struct{}, len(skus))
+	for _, batch := range batches(skus, c.opts.batchSize) {
+		cmds := make([]*redis.BoolCmd, len(batch))
+		pipe := c.client.Pipeline()
+		for i, sku := range batch {
+			cmds[i] = pipe.SIsMember(ctx, c.key, sku)
+		}
+		if _, err := pipe.Exec(ctx); err != nil {
+			return nil, errors.Wrapf(err, "failed to look up skus in %s", c.key)
+		}
+		for i, cmd := range cmds {
+			if cmd.Val() {
+				res[batch[i]] = struct{}{}
+			}
+		}
+	}
+	return res, nil
+}
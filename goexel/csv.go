@@ -0,0 +1,170 @@
+package goexel
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"path"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/xuri/excelize/v2"
+	"gitlab.ozon.ru/platform/tracer-go/logger"
+)
+
+// Format - формат входного файла
+type Format string
+
+const (
+	FormatXLSX Format = "xlsx"
+	FormatCSV  Format = "csv"
+	FormatTSV  Format = "tsv"
+)
+
+// CSVSheet - лист, на который раскладываем csv, чтобы go-xlsx декодировал его как обычную книгу
+// в замечаниях и отчете строки csv будут с этим листом
+const CSVSheet = "csv"
+
+// csvNotesHeader - колонка, которую дописываем в размеченный csv
+const csvNotesHeader = "Замечания валидации"
+
+// DetectFormat - формат по расширению файла, по умолчанию xlsx
+func DetectFormat(name string) Format {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".csv":
+		return FormatCSV
+	case ".tsv", ".tab":
+		return FormatTSV
+	}
+	return FormatXLSX
+}
+
+// Ext - расширение для выходного файла в этом формате
+func (fm Format) Ext() string {
+	return "." + string(fm)
+}
+
+func (fm Format) comma() rune {
+	if fm == FormatTSV {
+		return '\t'
+	}
+	return ','
+}
+
+// NewFileByFormat - NewFile, который понимает и csv/tsv
+func NewFileByFormat[T any](file []byte, format Format, opts ...opt) (*File[T], error) {
+	switch format {
+	case FormatCSV, FormatTSV:
+		return NewCSVFile[T](file, format.comma(), opts...)
+	}
+	return NewFile[T](file, opts...)
+}
+
+// NewCSVFile - раскладывает csv на лист CSVSheet и декодирует его так же как xlsx,
+// поэтому теги xlsx, правила xlsx-validation и позиции ячеек работают без изменений.
+// GetFileBytes у такого файла отдает исходный csv с дописанной колонкой замечаний
+func NewCSVFile[T any](file []byte, comma rune, opts ...opt) (*File[T], error) {
+	records, err := readCSV(file, comma)
+	if err != nil {
+		return nil, err
+	}
+
+	book := excelize.NewFile()
+	book.SetSheetName(book.GetSheetName(0), CSVSheet)
+	for i := range records {
+		axis, _ := excelize.CoordinatesToCellName(1, i+1)
+		if err = book.SetSheetRow(CSVSheet, axis, &records[i]); err != nil {
+			return nil, errors.Wrap(err, "Ошибка чтения csv")
+		}
+	}
+	buf, err := book.WriteToBuffer()
+	if err != nil {
+		return nil, errors.Wrap(err, "Ошибка чтения csv")
+	}
+
+	return NewFile[T](buf.Bytes(), append(opts, withCSVOutput(comma))...)
+}
+
+func readCSV(file []byte, comma rune) ([][]string, error) {
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(file, []byte("\xef\xbb\xbf"))))
+	reader.Comma = comma
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, errors.Wrap(err, "Ошибка чтения csv")
+	}
+	if len(records) == 0 {
+		return nil, errors.New("Пустой csv")
+	}
+	return records, nil
+}
+
+// withCSVOutput - GetFileBytes вернет csv с разделителем comma
+func withCSVOutput(comma rune) opt {
+	return func(f *FileCellRegisterer) {
+		f.csvComma = comma
+	}
+}
+
+// csvBytes - текущий лист с записанными значениями и колонкой замечаний, вызывать под commMu.
+// Замечания берем из комментариев готовой книги, так в csv попадают и те, что написал декодер
+func (f FileCellRegisterer) csvBytes(ctx context.Context, comma rune) []byte {
+	annotated, err := excelize.OpenReader(bytes.NewReader(f.commentRegisterer.GetFileBytesWithComments()))
+	if err != nil {
+		logger.Errorf(ctx, "failed to read annotated file: %v", err)
+		return nil
+	}
+	rows, err := annotated.GetRows(f.sheet)
+	if err != nil {
+		logger.Errorf(ctx, "failed to read annotated rows: %v", err)
+		return nil
+	}
+
+	var (
+		width int
+		notes = make(map[int][]string)
+	)
+	for _, row := range rows {
+		if len(row) > width {
+			width = len(row)
+		}
+	}
+	for _, comment := range annotated.GetComments()[f.sheet] {
+		col, row, err := excelize.CellNameToCoordinates(comment.Ref)
+		if err != nil {
+			continue
+		}
+		text := commentText(comment)
+		if header := f.header(f.sheet, col); row > 1 && header != "" {
+			text = header + ": " + text
+		}
+		notes[row] = append(notes[row], text)
+		for len(rows) < row {
+			rows = append(rows, nil)
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	writer := csv.NewWriter(buf)
+	writer.Comma = comma
+	for i, row := range rows {
+		record := make([]string, width+1)
+		copy(record, row)
+		record[width] = strings.Join(notes[i+1], "; ")
+		if i == 0 {
+			record[width] = csvNotesHeader
+		}
+		if err = writer.Write(record); err != nil {
+			logger.Errorf(ctx, "failed to write csv: %v", err)
+			return nil
+		}
+	}
+	writer.Flush()
+	if err = writer.Error(); err != nil {
+		logger.Errorf(ctx, "failed to write csv: %v", err)
+		return nil
+	}
+	return buf.Bytes()
+}
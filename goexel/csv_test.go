@@ -0,0 +1,44 @@
+package goexel
+
+import (
+	"encoding/csv"
+	"strings"
+	"testing"
+)
+
+func TestCSVFile(t *testing.T) {
+	input := "\xef\xbb\xbfSKU\tНазвание\n1\tчайник\n2\t\"кружка, 300 мл\"\n"
+	if DetectFormat("promo.TSV") != FormatTSV {
+		t.Fatal("tsv is not detected")
+	}
+	file, err := NewFileByFormat[sheetRow]([]byte(input), FormatTSV, WithSummarySheet())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(file.Table) != 2 || file.Table[1].Name.Value != "кружка, 300 мл" || file.Table[1].Name.GetRowNumber() != 3 {
+		t.Fatalf("unexpected rows: %+v", file.Table)
+	}
+
+	file.CellRegister.RegisterCommentByValue(&file.Table[1].Sku, "Нет такого СКУ")
+	file.CellRegister.RegisterCellValueByPosition([]string{"проверено"}, 3, 2)
+
+	reader := csv.NewReader(strings.NewReader(string(file.CellRegister.GetFileBytes())))
+	reader.Comma = '\t'
+	records, err := reader.ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := [][]string{
+		{"SKU", "Название", "", csvNotesHeader},
+		{"1", "чайник", "проверено", ""},
+		{"2", "кружка, 300 мл", "", "SKU: Нет такого СКУ"},
+	}
+	if len(records) != len(expected) {
+		t.Fatalf("unexpected csv: %v", records)
+	}
+	for i := range expected {
+		if strings.Join(records[i], "|") != strings.Join(expected[i], "|") {
+			t.Fatalf("row %d: expected %v, got %v", i+1, expected[i], records[i])
+		}
+	}
+}
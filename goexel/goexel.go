@@ -3,6 +3,7 @@ package goexel
 import (
 	"bytes"
 	"context"
+	"reflect"
 	"strconv"
 	"strings"
 	"sync"
@@ -14,7 +15,8 @@ import (
 )
 
 func SetFileContext[T any](ctx context.Context, f *File[T]) context.Context {
-	return context.WithValue(ctx, ctxkey, f)
+	ctx = context.WithValue(ctx, ctxkey, f)
+	return context.WithValue(ctx, streamerKey, Streamer(f))
 }
 
 func GetFileFromContext[T any](ctx context.Context) *File[T] {
@@ -26,48 +28,295 @@ func GetFileFromContext[T any](ctx context.Context) *File[T] {
 type ctxFileKey int8
 
 const (
-	ctxkey ctxFileKey = 1
+	ctxkey      ctxFileKey = 1
+	streamerKey ctxFileKey = 2
 )
 
 type File[T any] struct {
 	Table        []*T
 	CellRegister *FileCellRegisterer
+	// Sheets - листы, из которых собрана Table, каждая строка помнит свой лист
+	Sheets []string
+
+	book     *excelize.File
+	comments *goxlsx.ValidationRegister
+	// wholeBook - декодер читает книгу как есть, без разбора по листам
+	wholeBook bool
+	// stream - не nil, пока строки не декодированы в Table, см NewStreamFile
+	stream *stream[T]
 }
 
-func NewFile[T any](file []byte) (*File[T], error) {
+func NewFile[T any](file []byte, opts ...opt) (*File[T], error) {
+	res, err := openFile[T](file, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return res, res.Materialize()
+}
+
+// openFile - открывает книгу и находит листы с таблицей, но строки не декодирует
+func openFile[T any](file []byte, opts ...opt) (*File[T], error) {
 	reader := bytes.NewReader(file)
 	f, err := excelize.OpenReader(reader)
 	if err != nil {
 		return nil, errors.Wrap(err, "Ошибка чтения файла")
 	}
 
-	decoder := goxlsx.NewDecoder(f)
 	commentRegister := goxlsx.NewValidationRegister(f)
-	register, err := NewFileRegisterer(f, commentRegister)
+	register, err := NewFileRegisterer(f, commentRegister, opts...)
 	if err != nil {
 		return nil, err
 	}
 
-	decoder.AddRegister(commentRegister)
+	res := &File[T]{
+		CellRegister: register,
+		Sheets:       matchingSheets[T](f),
+		book:         f,
+		comments:     commentRegister,
+	}
+	switch {
+	case register.onlySheet != "":
+		if f.GetSheetIndex(register.onlySheet) < 0 {
+			return nil, errors.Errorf("Лист %s не найден", register.onlySheet)
+		}
+		res.Sheets = []string{register.onlySheet}
+	case len(f.GetSheetList()) == 1 || len(res.Sheets) == 0:
+		res.wholeBook = true
+		res.Sheets = []string{f.GetSheetName(f.GetActiveSheetIndex())}
+	}
+	register.SetSheet(res.Sheets[0])
+	return res, nil
+}
+
+// Materialize - декодирует все строки в Table, если они еще не там
+func (f *File[T]) Materialize() error {
+	if f.Table != nil {
+		return nil
+	}
+	if f.stream != nil && f.stream.started {
+		return errors.New("Строки файла уже прочитаны потоком")
+	}
+	f.stream = nil
+
+	before := bookComments(f.book)
 	resArr := make([]*T, 0)
-	decoder.Decode(&resArr)
+	if f.wholeBook {
+		decoder := goxlsx.NewDecoder(f.book)
+		decoder.AddRegister(f.comments)
+		decoder.Decode(&resArr)
+	} else {
+		for _, sheet := range f.Sheets {
+			rows, err := decodeSheet[T](f.book, sheet, f.comments)
+			if err != nil {
+				return err
+			}
+			resArr = append(resArr, rows...)
+		}
+	}
+	f.CellRegister.addDecoderFindings(before)
+	f.Table = resArr
+	return nil
+}
 
-	return &File[T]{
-		Table:        resArr,
-		CellRegister: register,
-	}, nil
+// Len - сколько строк в таблице, для потокового файла считается по листу заранее
+func (f *File[T]) Len() int {
+	if f.Streaming() {
+		return f.stream.len
+	}
+	return len(f.Table)
+}
+
+// decodeSheet - декодер читает один лист, поэтому даем ему книгу в памяти, куда из book переписан только sheet.
+// Строки ложатся на те же номера и лист называется так же, так что замечания декодера попадают в исходный файл на место
+func decodeSheet[T any](book *excelize.File, sheet string, commentRegister *goxlsx.ValidationRegister) ([]*T, error) {
+	sheetFile, rowsCount, err := copySheet(book, sheet)
+	if err != nil {
+		return nil, errors.Wrap(err, "Ошибка чтения файла")
+	}
+
+	decoder := goxlsx.NewDecoder(sheetFile)
+	decoder.AddRegister(commentRegister)
+	res := make([]*T, 0, rowsCount)
+	decoder.Decode(&res)
+	return res, nil
+}
+
+// copySheet - новая книга с единственным листом sheet из book. Ячейки переносятся сырыми значениями с типом
+// и числовым форматом, иначе даты и числа пришли бы декодеру уже отформатированными строками
+func copySheet(book *excelize.File, sheet string) (*excelize.File, int, error) {
+	rows, err := book.GetRows(sheet, excelize.Options{RawCellValue: true})
+	if err != nil {
+		return nil, 0, err
+	}
+	res := excelize.NewFile()
+	res.SetSheetName(res.GetSheetName(0), sheet)
+	// стиль book -> стиль res с тем же числовым форматом
+	styles := make(map[int]int)
+	for i, row := range rows {
+		for j, raw := range row {
+			if raw == "" {
+				continue
+			}
+			cell, _ := excelize.CoordinatesToCellName(j+1, i+1)
+			if err = copyCell(book, res, sheet, cell, raw, styles); err != nil {
+				return nil, 0, err
+			}
+		}
+	}
+	return res, len(rows), nil
+}
+
+func copyCell(from, to *excelize.File, sheet, cell, raw string, styles map[int]int) error {
+	cellType, err := from.GetCellType(sheet, cell)
+	if err != nil {
+		return err
+	}
+	switch cellType {
+	case excelize.CellTypeBool:
+		err = to.SetCellBool(sheet, cell, raw == "1")
+	case excelize.CellTypeString, excelize.CellTypeError:
+		err = to.SetCellStr(sheet, cell, raw)
+	default:
+		// числа и даты, у них тип обычно не указан
+		if number, parseErr := strconv.ParseFloat(raw, 64); parseErr == nil {
+			err = to.SetCellFloat(sheet, cell, number, -1, 64)
+		} else {
+			err = to.SetCellStr(sheet, cell, raw)
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	base, err := from.GetCellStyle(sheet, cell)
+	if err != nil || base == 0 {
+		return err
+	}
+	style, exists := styles[base]
+	if !exists {
+		if style, err = to.NewStyle(numFmtStyle(from, base)); err != nil {
+			return err
+		}
+		styles[base] = style
+	}
+	return to.SetCellStyle(sheet, cell, cell, style)
+}
+
+// matchingSheets - листы, в шапке которых есть все обязательные колонки T
+// если обязательных нет, то хватит любой колонки из тегов xlsx
+func matchingSheets[T any](f *excelize.File) (res []string) {
+	var (
+		rowType  = reflect.TypeOf((*T)(nil)).Elem()
+		required []string
+		known    = make(map[string]struct{})
+	)
+	if rowType.Kind() != reflect.Struct {
+		return nil
+	}
+	for i := 0; i < rowType.NumField(); i++ {
+		field := rowType.Field(i)
+		header := strings.TrimSpace(field.Tag.Get("xlsx"))
+		if header == "" {
+			continue
+		}
+		known[header] = struct{}{}
+		if strings.Contains(field.Tag.Get("xlsx-validation"), "required") {
+			required = append(required, header)
+		}
+	}
+
+	for _, sheet := range f.GetSheetList() {
+		headers := make(map[string]struct{})
+		if rows, err := f.Rows(sheet); err == nil {
+			if rows.Next() {
+				columns, _ := rows.Columns()
+				for _, column := range columns {
+					headers[strings.TrimSpace(column)] = struct{}{}
+				}
+			}
+			_ = rows.Close()
+		}
+
+		matches := len(required) != 0
+		for _, header := range required {
+			if _, exists := headers[header]; !exists {
+				matches = false
+				break
+			}
+		}
+		if len(required) == 0 {
+			for header := range headers {
+				if _, exists := known[header]; exists {
+					matches = true
+					break
+				}
+			}
+		}
+		if matches {
+			res = append(res, sheet)
+		}
+	}
+	return res
 }
 
 // FileCellRegisterer - сущность которая добавляет строковые значения прямиком в ячейки таблицы.
 // Если не использовать в конце SaveValuesToFile
 type FileCellRegisterer struct {
 	sheet             string
-	cellValues        map[string][]string
+	cellValues        map[sheetCell][]string
 	cellValMu         *sync.Mutex
 	file              *excelize.File
 	style             int
 	commentRegisterer *goxlsx.ValidationRegister
 	commMu            *sync.Mutex
+	// jobID - от чьего имени пишутся замечания, см ForJob
+	jobID    string
+	findings *findings
+	// severity - серьезность замечаний этой копии регистратора, см WithSeverity
+	severity Severity
+	fills    map[Severity]string
+	// summary - добавлять ли лист итогов, см WithSummarySheet
+	summary bool
+	// csvComma - файл пришел из csv и отдавать его нужно так же, см NewCSVFile
+	csvComma rune
+	// rowOffset - сдвиг позиций строк пачки потокового файла, см WithRowOffset
+	rowOffset int
+	// onlySheet - таблицу берем только с этого листа, см WithSheet
+	onlySheet string
+	// notes - куда запоминать замечания, см Recording
+	notes *Notes
+}
+
+// findings - общие для всех копий регистратора замечания
+type findings struct {
+	mu      *sync.Mutex
+	list    []Finding
+	headers map[string][]string
+}
+
+type sheetCell struct {
+	sheet, cell string
+}
+
+// OnSheet - копия регистратора, которая пишет замечания по позиции на лист sheet, а не на тот что в SetSheet
+// нужна когда строки собраны из нескольких листов
+func (f *FileCellRegisterer) OnSheet(sheet string) *FileCellRegisterer {
+	if f == nil || sheet == "" {
+		return f
+	}
+	res := *f
+	res.sheet = sheet
+	return &res
+}
+
+// ForJob - копия регистратора, которая подписывает замечания jobID, пишет в те же ячейки и комментарии
+func (f *FileCellRegisterer) ForJob(jobID string) *FileCellRegisterer {
+	if f == nil {
+		return nil
+	}
+	res := *f
+	res.jobID = jobID
+	return &res
 }
 
 // GetFileBytes - записывает все комментарии и значения ячеек в файл, а затем отдает его байты
@@ -75,6 +324,22 @@ func (f FileCellRegisterer) GetFileBytes() []byte {
 	f.commMu.Lock()
 	defer f.commMu.Unlock()
 	f.saveValuesToFile(context.Background())
+	if f.csvComma != 0 {
+		return f.csvBytes(context.Background(), f.csvComma)
+	}
+	return f.commentRegisterer.GetFileBytesWithComments()
+}
+
+// GetFileBytesAs - GetFileBytes в заданном формате независимо от того, в каком файл пришел.
+// В csv попадает только текущий лист регистратора
+func (f FileCellRegisterer) GetFileBytesAs(format Format) []byte {
+	f.commMu.Lock()
+	defer f.commMu.Unlock()
+	f.saveValuesToFile(context.Background())
+	switch format {
+	case FormatCSV, FormatTSV:
+		return f.csvBytes(context.Background(), format.comma())
+	}
 	return f.commentRegisterer.GetFileBytesWithComments()
 }
 
@@ -97,6 +362,8 @@ func (f FileCellRegisterer) RegisterComment(message string) {
 	f.commMu.Lock()
 	f.commentRegisterer.Register(message)
 	f.commMu.Unlock()
+	f.note(NoteFile, "", 0, 0, message)
+	f.addFinding(Finding{Severity: f.severityOr(SeverityError), Message: message})
 }
 
 // RegisterCommentByCol добавляет комментарий к колонке первой записи листа
@@ -104,20 +371,26 @@ func (f FileCellRegisterer) RegisterCommentByCol(message string, col int) {
 	f.commMu.Lock()
 	f.commentRegisterer.RegisterByCol(f.sheet, message, col)
 	f.commMu.Unlock()
+	f.note(NoteColumn, f.sheet, col, 0, message)
+	f.addFinding(Finding{Column: col, Severity: f.severityOr(SeverityError), Message: message})
 }
 
 // RegisterCommentByPosition добавляет комментарий к ячейке шаблона
 func (f FileCellRegisterer) RegisterCommentByPosition(message string, col int, row int) {
 	f.commMu.Lock()
-	f.commentRegisterer.RegisterByPosition(f.sheet, message, col, row)
+	f.commentRegisterer.RegisterByPosition(f.sheet, message, col, f.row(row))
 	f.commMu.Unlock()
+	f.note(NoteCell, f.sheet, col, row, message)
+	f.addFinding(Finding{Row: f.row(row), Column: col, Severity: f.severityOr(SeverityError), Message: message})
 }
 
 // RegisterCommentByRow добавляет комментарий к строке первой колонки листа
 func (f FileCellRegisterer) RegisterCommentByRow(message string, row int) {
 	f.commMu.Lock()
-	f.commentRegisterer.RegisterByRow(f.sheet, message, row)
+	f.commentRegisterer.RegisterByRow(f.sheet, message, f.row(row))
 	f.commMu.Unlock()
+	f.note(NoteCell, f.sheet, 1, row, message)
+	f.addFinding(Finding{Row: f.row(row), Column: 1, Severity: f.severityOr(SeverityError), Message: message})
 }
 
 // RegisterCommentBySheet - добавляет комментарий к первой ячейке шаблона
@@ -125,20 +398,46 @@ func (f FileCellRegisterer) RegisterCommentBySheet(message string) {
 	f.commMu.Lock()
 	f.commentRegisterer.RegisterBySheet(f.sheet, message)
 	f.commMu.Unlock()
+	f.note(NoteSheet, f.sheet, 0, 0, message)
+	f.addFinding(Finding{Severity: f.severityOr(SeverityError), Message: message})
 }
 
 // RegisterCommentByValue - добавляет комментарий к ячейке шаблона
 func (f FileCellRegisterer) RegisterCommentByValue(value goxlsx.Type, message string) {
 	f.commMu.Lock()
-	f.commentRegisterer.RegisterByValue(value, message)
+	if f.rowOffset != 0 {
+		f.commentRegisterer.RegisterByPosition(value.GetSheetName(), message, value.GetColumnNumber(), f.row(value.GetRowNumber()))
+	} else {
+		f.commentRegisterer.RegisterByValue(value, message)
+	}
 	f.commMu.Unlock()
+	f.note(NoteCell, value.GetSheetName(), value.GetColumnNumber(), value.GetRowNumber(), message)
+	f.addFinding(f.findingByValue(value, f.severityOr(SeverityError), message))
 }
 
 // RegisterCommentNotExist - добавляет комментарий, что данные о записи не найдены
 func (f FileCellRegisterer) RegisterCommentNotExist(value goxlsx.Type) {
 	f.commMu.Lock()
-	f.commentRegisterer.RegisterNotExist(value)
+	if f.rowOffset != 0 {
+		f.commentRegisterer.RegisterByPosition(value.GetSheetName(), notExistMessage, value.GetColumnNumber(), f.row(value.GetRowNumber()))
+	} else {
+		f.commentRegisterer.RegisterNotExist(value)
+	}
 	f.commMu.Unlock()
+	f.note(NoteCell, value.GetSheetName(), value.GetColumnNumber(), value.GetRowNumber(), notExistMessage)
+	f.addFinding(f.findingByValue(value, f.severityOr(SeverityError), notExistMessage))
+}
+
+const notExistMessage = "Данные не найдены"
+
+func (f FileCellRegisterer) findingByValue(value goxlsx.Type, severity Severity, message string) Finding {
+	return Finding{
+		Sheet:    value.GetSheetName(),
+		Row:      f.row(value.GetRowNumber()),
+		Column:   value.GetColumnNumber(),
+		Severity: severity,
+		Message:  message,
+	}
 }
 
 // SetSheet -  необходимо перед всем командами register (кроме импользующих goxlsx.Type) и просто RegisterComment
@@ -150,7 +449,7 @@ func (f *FileCellRegisterer) SetSheet(sheet string) {
 
 // RegisterCellValueByString - добавляет строки в ячейку шаблона
 func (f *FileCellRegisterer) RegisterCellValueByString(messages []string, value goxlsx.String) {
-	f.RegisterCellValueByPosition(messages, value.GetColumnNumber(), value.GetRowNumber())
+	f.OnSheet(value.GetSheetName()).RegisterCellValueByPosition(messages, value.GetColumnNumber(), value.GetRowNumber())
 }
 
 // RegisterCellValueByPosition добавляет строки прямиком к ячейке с колонкой и строкой
@@ -162,22 +461,31 @@ func (f *FileCellRegisterer) RegisterCellValueByPosition(messages []string, col,
 	if row == 0 {
 		row = 1
 	}
+	f.note(NoteValue, f.sheet, col, row, messages...)
+	row = f.row(row)
 
 	column, _ := excelize.ColumnNumberToName(col)
-	cell := column + strconv.Itoa(row)
+	cell := sheetCell{sheet: f.sheet, cell: column + strconv.Itoa(row)}
 	f.cellValues[cell] = append(f.cellValues[cell], messages...)
 	f.cellValMu.Unlock()
+
+	// сообщения в ячейках - не строгие ошибки, как колонка "Ошибка" в шаблоне
+	for _, message := range messages {
+		f.addFinding(Finding{Row: row, Column: col, Severity: f.severityOr(SeverityWarning), Message: message})
+	}
 }
 
 func (f *FileCellRegisterer) saveValuesToFile(ctx context.Context) {
-	for cell, value := range f.cellValues {
-		if err := f.file.SetCellStyle(f.sheet, cell, cell, f.style); err != nil {
+	for key, value := range f.cellValues {
+		if err := f.file.SetCellStyle(key.sheet, key.cell, key.cell, f.style); err != nil {
 			logger.Errorf(ctx, "failed to set cell style: %v", err)
 		}
-		if err := f.file.SetCellStr(f.sheet, cell, strings.Join(value, "\n ")); err != nil {
+		if err := f.file.SetCellStr(key.sheet, key.cell, strings.Join(value, "\n ")); err != nil {
 			logger.Errorf(ctx, "failed to set cell value: %v", err)
 		}
 	}
+	f.highlightCells(ctx)
+	f.writeSummary(ctx)
 }
 
 // HasCellEntries - показывает были ли сделаны записи прямиком в ячейки
@@ -205,20 +513,32 @@ func WithCellStyle(cellStyle *excelize.Style) opt {
 		}
 		style, err := f.file.NewStyle(cellStyle)
 		if err != nil {
-			logger.Errorf(context.Background(), "failed to set cell style: %v")
+			logger.Errorf(context.Background(), "failed to set cell style: %v", err)
 		}
 		f.style = style
 	}
 }
 
+// WithSheet - таблица берется только с листа sheet, даже если подходят и другие
+func WithSheet(sheet string) opt {
+	return func(f *FileCellRegisterer) {
+		f.onlySheet = sheet
+	}
+}
+
 // NewFileRegisterer - создает сущность, которая в file записывает строки в ячейки или в комментарии к ним
 func NewFileRegisterer(file *excelize.File, commentRegisterer *goxlsx.ValidationRegister, opts ...opt) (*FileCellRegisterer, error) {
 	f := &FileCellRegisterer{
 		cellValMu:         &sync.Mutex{},
-		cellValues:        make(map[string][]string),
+		cellValues:        make(map[sheetCell][]string),
 		file:              file,
 		commentRegisterer: commentRegisterer,
 		commMu:            &sync.Mutex{},
+		findings: &findings{
+			mu:      &sync.Mutex{},
+			headers: make(map[string][]string),
+		},
+		fills: DefaultSeverityFills,
 	}
 	for _, opt := range opts {
 		opt(f)
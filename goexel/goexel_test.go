@@ -0,0 +1,205 @@
+package goexel
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+	goxlsx "gitlab.ozon.ru/express/platform/lib/go-xlsx"
+)
+
+type sheetRow struct {
+	Sku  goxlsx.String `xlsx:"SKU" xlsx-validation:"required"`
+	Name goxlsx.String `xlsx:"Название"`
+}
+
+// newMonthsBook - книга с таблицей на листах Март и Апрель и посторонним листом между ними
+func newMonthsBook(t *testing.T) []byte {
+	t.Helper()
+	book := excelize.NewFile()
+	book.SetSheetName("Sheet1", "Март")
+	book.NewSheet("Справка")
+	book.NewSheet("Апрель")
+	cells := map[string]map[string]string{
+		"Март":    {"A1": "SKU", "B1": "Название", "A2": "1", "A3": "2"},
+		"Справка": {"A1": "Комментарий", "A2": "не трогать"},
+		"Апрель":  {"A1": "SKU", "B1": "Название", "A2": "3"},
+	}
+	for sheet, values := range cells {
+		for cell, value := range values {
+			if err := book.SetCellStr(sheet, cell, value); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	buf, err := book.WriteToBuffer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestNewFileReadsEverySheet(t *testing.T) {
+	file, err := NewFile[sheetRow](newMonthsBook(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(file.Sheets) != 2 || file.Sheets[0] != "Март" || file.Sheets[1] != "Апрель" {
+		t.Fatalf("unexpected sheets: %v", file.Sheets)
+	}
+	if len(file.Table) != 3 || file.Table[2].Sku.GetSheetName() != "Апрель" {
+		t.Fatalf("rows are not collected from every sheet: %d", len(file.Table))
+	}
+
+	for _, row := range file.Table {
+		file.CellRegister.OnSheet(row.Sku.GetSheetName()).RegisterCellValueByPosition([]string{row.Sku.Value}, 3, row.Sku.GetRowNumber())
+	}
+	reopened, err := excelize.OpenReader(bytes.NewReader(file.CellRegister.GetFileBytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for sheet, expected := range map[string]string{"Март": "1", "Апрель": "3"} {
+		value, err := reopened.GetCellValue(sheet, "C2")
+		if err != nil || value != expected {
+			t.Fatalf("%s!C2: expected %q, got %q (%v)", sheet, expected, value, err)
+		}
+	}
+}
+
+func TestWithSheet(t *testing.T) {
+	if _, err := NewFile[sheetRow](newMonthsBook(t), WithSheet("Май")); err == nil {
+		t.Fatal("expected error for a missing sheet")
+	}
+
+	file, err := NewFile[sheetRow](newMonthsBook(t), WithSheet("Апрель"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(file.Table) != 1 || file.Table[0].Sku.Value != "3" || file.Table[0].Sku.GetSheetName() != "Апрель" {
+		t.Fatalf("unexpected rows: %+v", file.Table)
+	}
+
+	file.CellRegister.RegisterCommentByValue(&file.Table[0].Sku, "Нет такого СКУ")
+	expected := "SKU,Название," + csvNotesHeader + "\n3,,SKU: Нет такого СКУ\n"
+	if res := string(file.CellRegister.GetFileBytesAs(FormatCSV)); res != expected {
+		t.Fatalf("expected csv %q, got %q", expected, res)
+	}
+}
+
+// строки после пропуска в середине листа сохраняют свои номера, книга при этом не переоткрывается на каждый лист
+func TestNewFileKeepsRowNumbers(t *testing.T) {
+	book := excelize.NewFile()
+	book.NewSheet("Апрель")
+	for sheet, values := range map[string]map[string]string{
+		"Sheet1": {"A1": "SKU", "A2": "1", "A4": "2"},
+		"Апрель": {"A1": "SKU", "A3": "3"},
+	} {
+		for cell, value := range values {
+			_ = book.SetCellStr(sheet, cell, value)
+		}
+	}
+	buf, err := book.WriteToBuffer()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := NewFile[sheetRow](buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[string]int)
+	for _, row := range file.Table {
+		if !row.Sku.IsEmpty() {
+			got[row.Sku.GetSheetName()+"!"+row.Sku.Value] = row.Sku.GetRowNumber()
+		}
+	}
+	expected := map[string]int{"Sheet1!1": 2, "Sheet1!2": 4, "Апрель!3": 3}
+	if len(got) != len(expected) {
+		t.Fatalf("expected rows %v, got %v", expected, got)
+	}
+	for key, row := range expected {
+		if got[key] != row {
+			t.Fatalf("expected rows %v, got %v", expected, got)
+		}
+	}
+}
+
+type typedRow struct {
+	Sku   goxlsx.String  `xlsx:"SKU" xlsx-validation:"required"`
+	From  goxlsx.Date    `xlsx:"Дата начала"`
+	Price goxlsx.Float64 `xlsx:"Цена"`
+}
+
+// newTypedBook - таблица с датой и числом на листе Промо, с листом инструкции перед ней или без него
+func newTypedBook(t *testing.T, withInstructions bool) *excelize.File {
+	t.Helper()
+	book := excelize.NewFile()
+	book.SetSheetName("Sheet1", "Промо")
+	if withInstructions {
+		book.NewSheet("Инструкция")
+		_ = book.SetCellStr("Инструкция", "A1", "заполните лист Промо")
+	}
+	for cell, value := range map[string]interface{}{
+		"A1": "SKU", "B1": "Дата начала", "C1": "Цена",
+		"A2": "1", "B2": time.Date(2023, 3, 1, 0, 0, 0, 0, time.UTC), "C2": 1234.5,
+	} {
+		if err := book.SetCellValue("Промо", cell, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	dateFormat := "dd.mm.yyyy"
+	style, err := book.NewStyle(&excelize.Style{CustomNumFmt: &dateFormat})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = book.SetCellStyle("Промо", "B2", "B2", style); err != nil {
+		t.Fatal(err)
+	}
+	return book
+}
+
+// даты и числа листа доходят до декодера теми же ячейками, что и в файле, а не отформатированными строками
+func TestNewFileKeepsCellTypes(t *testing.T) {
+	book := newTypedBook(t, true)
+	sheetFile, _, err := copySheet(book, "Промо")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, cell := range []string{"A2", "B2", "C2"} {
+		expectedType, _ := book.GetCellType("Промо", cell)
+		gotType, _ := sheetFile.GetCellType("Промо", cell)
+		if gotType != expectedType {
+			t.Fatalf("%s: expected type %v, got %v", cell, expectedType, gotType)
+		}
+		for _, opts := range []excelize.Options{{}, {RawCellValue: true}} {
+			expected, _ := book.GetCellValue("Промо", cell, opts)
+			got, _ := sheetFile.GetCellValue("Промо", cell, opts)
+			if got != expected {
+				t.Fatalf("%s (raw=%v): expected %q, got %q", cell, opts.RawCellValue, expected, got)
+			}
+		}
+	}
+
+	// лист из книги с инструкцией декодируется так же, как книга из одного этого листа
+	var decoded []*typedRow
+	for _, withInstructions := range []bool{false, true} {
+		buf, err := newTypedBook(t, withInstructions).WriteToBuffer()
+		if err != nil {
+			t.Fatal(err)
+		}
+		file, err := NewFile[typedRow](buf.Bytes())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(file.Table) != 1 {
+			t.Fatalf("expected one row, got %d", len(file.Table))
+		}
+		decoded = append(decoded, file.Table[0])
+	}
+	whole, sheet := decoded[0], decoded[1]
+	if !sheet.From.Value.Equal(whole.From.Value) || sheet.From.IsValid() != whole.From.IsValid() ||
+		sheet.Price.Value != whole.Price.Value || sheet.Price.Value != 1234.5 {
+		t.Fatalf("sheet decoded as %+v, whole book as %+v", sheet, whole)
+	}
+}
@@ -0,0 +1,32 @@
+// Package goexeltest - книги для тестов пакетов, которые проверяют файлы через goexel
+package goexeltest
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// SkuBook - книга с колонками SKU и Комментарий, по строке на каждый из skus.
+// Комментарий пустой, его и оформление строк тест дописывает сам
+func SkuBook(t testing.TB, skus ...string) *excelize.File {
+	t.Helper()
+	book := excelize.NewFile()
+	_ = book.SetCellStr("Sheet1", "A1", "SKU")
+	_ = book.SetCellStr("Sheet1", "B1", "Комментарий")
+	for i, sku := range skus {
+		_ = book.SetCellStr("Sheet1", "A"+strconv.Itoa(i+2), sku)
+	}
+	return book
+}
+
+// Bytes - содержимое книги, как его загружают goexel.NewFile и goexel.NewStreamFile
+func Bytes(t testing.TB, book *excelize.File) []byte {
+	t.Helper()
+	buf, err := book.WriteToBuffer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,108 @@
+package goexel
+
+import (
+	"sync"
+)
+
+// NoteKind - каким методом регистратора было записано замечание
+type NoteKind string
+
+const (
+	// NoteFile - RegisterComment
+	NoteFile NoteKind = "file"
+	// NoteSheet - RegisterCommentBySheet
+	NoteSheet NoteKind = "sheet"
+	// NoteColumn - RegisterCommentByCol
+	NoteColumn NoteKind = "column"
+	// NoteCell - комментарий к ячейке: RegisterCommentByPosition, ByRow, ByValue и NotExist
+	NoteCell NoteKind = "cell"
+	// NoteValue - запись прямо в ячейку, RegisterCellValueByPosition
+	NoteValue NoteKind = "value"
+)
+
+// Note - замечание, которое записала Recording копия регистратора.
+// Если Sheet пустой, то замечание на листе строки и Row - смещение от нее, иначе Row - номер строки листа Sheet
+type Note struct {
+	Kind     NoteKind `json:"kind"`
+	Sheet    string   `json:"sheet,omitempty"`
+	Column   int      `json:"column,omitempty"`
+	Row      int      `json:"row,omitempty"`
+	Severity Severity `json:"severity,omitempty"`
+	Messages []string `json:"messages"`
+}
+
+// Notes - замечания одной строки, их пишут Recording копии регистратора
+type Notes struct {
+	mu    *sync.Mutex
+	sheet string
+	row   int
+	list  []Note
+}
+
+// List - записанные замечания в порядке записи
+func (n *Notes) List() []Note {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return append([]Note(nil), n.list...)
+}
+
+// Recording - копия регистратора, которая пишет как обычно, но еще и запоминает замечания строки row листа sheet,
+// чтобы потом повторить их через Replay на той же строке в другом файле, даже если строка переехала
+func (f *FileCellRegisterer) Recording(sheet string, row int) (*FileCellRegisterer, *Notes) {
+	notes := &Notes{mu: &sync.Mutex{}, sheet: sheet, row: row}
+	if f == nil {
+		return nil, notes
+	}
+	res := *f
+	res.notes = notes
+	return &res, notes
+}
+
+// note - запоминает замечание, если это Recording копия
+func (f FileCellRegisterer) note(kind NoteKind, sheet string, col, row int, messages ...string) {
+	if f.notes == nil {
+		return
+	}
+	note := Note{Kind: kind, Sheet: sheet, Column: col, Row: row, Severity: f.severity, Messages: messages}
+	if (kind == NoteCell || kind == NoteValue) && sheet == f.notes.sheet {
+		note.Sheet, note.Row = "", row-f.notes.row
+	}
+
+	f.notes.mu.Lock()
+	f.notes.list = append(f.notes.list, note)
+	f.notes.mu.Unlock()
+}
+
+// Replay - повторяет замечания, записанные Recording, для строки row листа sheet
+func (f *FileCellRegisterer) Replay(notes []Note, sheet string, row int) {
+	if f == nil {
+		return
+	}
+	for _, note := range notes {
+		reg := f
+		if note.Severity != "" {
+			reg = reg.WithSeverity(note.Severity)
+		}
+		noteSheet, noteRow := note.Sheet, note.Row
+		if noteSheet == "" {
+			noteSheet, noteRow = sheet, row+note.Row
+		}
+		reg = reg.OnSheet(noteSheet)
+
+		for _, message := range note.Messages {
+			switch note.Kind {
+			case NoteFile:
+				reg.RegisterComment(message)
+			case NoteSheet:
+				reg.RegisterCommentBySheet(message)
+			case NoteColumn:
+				reg.RegisterCommentByCol(message, note.Column)
+			case NoteCell:
+				reg.RegisterCommentByPosition(message, note.Column, noteRow)
+			}
+		}
+		if note.Kind == NoteValue {
+			reg.RegisterCellValueByPosition(note.Messages, note.Column, noteRow)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package goexel
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Severity - насколько серьезно замечание
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+	SeverityInfo    Severity = "info"
+)
+
+// Finding - одно замечание валидации в машиночитаемом виде
+// Row и Column равны 0, если замечание относится ко всему листу или файлу
+type Finding struct {
+	JobID    string   `json:"job_id,omitempty"`
+	Sheet    string   `json:"sheet,omitempty"`
+	Row      int      `json:"row,omitempty"`
+	Column   int      `json:"column,omitempty"`
+	Header   string   `json:"header,omitempty"`
+	Cell     string   `json:"cell,omitempty"`
+	Severity Severity `json:"severity"`
+	Message  string   `json:"message"`
+	Value    string   `json:"value,omitempty"`
+}
+
+// Report - все замечания по файлу и сводка по ним
+type Report struct {
+	Total      int              `json:"total"`
+	BySeverity map[Severity]int `json:"by_severity"`
+	ByJob      map[string]int   `json:"by_job"`
+	Findings   []Finding        `json:"findings"`
+}
+
+// addFinding - запоминает замечание, заголовок колонки и исходное значение ячейки достаем из файла
+func (f FileCellRegisterer) addFinding(finding Finding) {
+	finding.JobID = f.jobID
+	if finding.Sheet == "" {
+		finding.Sheet = f.sheet
+	}
+
+	f.commMu.Lock()
+	if finding.Column > 0 {
+		finding.Header = f.header(finding.Sheet, finding.Column)
+	}
+	if finding.Column > 0 && finding.Row > 0 {
+		finding.Cell, _ = excelize.CoordinatesToCellName(finding.Column, finding.Row)
+		finding.Value, _ = f.file.GetCellValue(finding.Sheet, finding.Cell)
+	}
+	f.commMu.Unlock()
+
+	f.findings.mu.Lock()
+	f.findings.list = append(f.findings.list, finding)
+	f.findings.mu.Unlock()
+}
+
+// addDecoderFinding - замечание декодера по тегам xlsx-validation, это всегда ошибка файла
+func (f FileCellRegisterer) addDecoderFinding(sheet string, col, row int, message string) {
+	f.addFinding(Finding{Sheet: sheet, Column: col, Row: row, Severity: SeverityError, Message: message})
+}
+
+// commentKey - комментарий книги, по ним отличаем замечания декодера от комментариев самого файла
+type commentKey struct {
+	sheet, ref, text string
+}
+
+// bookComments - сколько каких комментариев в книге
+func bookComments(book *excelize.File) map[commentKey]int {
+	res := make(map[commentKey]int)
+	for sheet, comments := range book.GetComments() {
+		for _, comment := range comments {
+			res[commentKey{sheet: sheet, ref: comment.Ref, text: commentText(comment)}]++
+		}
+	}
+	return res
+}
+
+// addDecoderFindings - декодер пишет замечания комментариями прямо в книгу, мимо регистратора, поэтому в отчет
+// переносим все комментарии, которых не было в before. Вызывать, пока джобы еще ничего не пишут
+func (f FileCellRegisterer) addDecoderFindings(before map[commentKey]int) {
+	for key, count := range bookComments(f.file) {
+		col, row, err := excelize.CellNameToCoordinates(key.ref)
+		if err != nil {
+			continue
+		}
+		for i := before[key]; i < count; i++ {
+			f.addDecoderFinding(key.sheet, col, row, key.text)
+		}
+	}
+}
+
+// header - заголовок колонки из первой строки листа, вызывать под commMu
+func (f FileCellRegisterer) header(sheet string, col int) string {
+	headers, cached := f.findings.headers[sheet]
+	if !cached {
+		rows, err := f.file.Rows(sheet)
+		if err == nil {
+			if rows.Next() {
+				headers, _ = rows.Columns()
+			}
+			_ = rows.Close()
+		}
+		f.findings.headers[sheet] = headers
+	}
+	if col > len(headers) {
+		return ""
+	}
+	return strings.TrimSpace(headers[col-1])
+}
+
+// Findings - все зарегистрированные замечания, отсортированные по листу и позиции
+func (f FileCellRegisterer) Findings() []Finding {
+	f.findings.mu.Lock()
+	res := make([]Finding, len(f.findings.list))
+	copy(res, f.findings.list)
+	f.findings.mu.Unlock()
+
+	sort.SliceStable(res, func(i, j int) bool {
+		if res[i].Sheet != res[j].Sheet {
+			return res[i].Sheet < res[j].Sheet
+		}
+		if res[i].Row != res[j].Row {
+			return res[i].Row < res[j].Row
+		}
+		return res[i].Column < res[j].Column
+	})
+	return res
+}
+
+// GetReport - замечания со сводкой по джобам и серьезности
+func (f FileCellRegisterer) GetReport() Report {
+	report := Report{
+		BySeverity: map[Severity]int{},
+		ByJob:      map[string]int{},
+		Findings:   f.Findings(),
+	}
+	report.Total = len(report.Findings)
+	for _, finding := range report.Findings {
+		report.BySeverity[finding.Severity]++
+		report.ByJob[finding.JobID]++
+	}
+	return report
+}
+
+// GetReportBytes - отчет в json для сервисов, которым не нужен xlsx
+func (f FileCellRegisterer) GetReportBytes() ([]byte, error) {
+	return json.MarshalIndent(f.GetReport(), "", "  ")
+}
@@ -0,0 +1,159 @@
+package goexel
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+	goxlsx "gitlab.ozon.ru/express/platform/lib/go-xlsx"
+)
+
+func newTestRegisterer(t *testing.T) *FileCellRegisterer {
+	t.Helper()
+	file := excelize.NewFile()
+	sheet := file.GetSheetName(0)
+	for cell, value := range map[string]string{"A1": "SKU", "B1": "Ошибка", "A2": "42"} {
+		if err := file.SetCellStr(sheet, cell, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	register, err := NewFileRegisterer(file, goxlsx.NewValidationRegister(file))
+	if err != nil {
+		t.Fatal(err)
+	}
+	register.SetSheet(sheet)
+	return register
+}
+
+func TestReport(t *testing.T) {
+	register := newTestRegisterer(t)
+
+	register.ForJob("sku").RegisterCommentByPosition("Плохой СКУ", 1, 2)
+	register.ForJob("soft").RegisterCellValueByPosition([]string{"раз", "два"}, 2, 2)
+
+	report := register.GetReport()
+	if report.Total != 3 || report.ByJob["sku"] != 1 || report.ByJob["soft"] != 2 {
+		t.Fatalf("unexpected summary: %+v", report)
+	}
+	if report.BySeverity[SeverityError] != 1 || report.BySeverity[SeverityWarning] != 2 {
+		t.Fatalf("unexpected severities: %+v", report.BySeverity)
+	}
+
+	expected := Finding{
+		JobID:    "sku",
+		Sheet:    "Sheet1",
+		Row:      2,
+		Column:   1,
+		Header:   "SKU",
+		Cell:     "A2",
+		Severity: SeverityError,
+		Message:  "Плохой СКУ",
+		Value:    "42",
+	}
+	if report.Findings[0] != expected {
+		t.Fatalf("expected %+v, got %+v", expected, report.Findings[0])
+	}
+
+	data, err := register.GetReportBytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded := Report{}
+	if err = json.Unmarshal(data, &decoded); err != nil || decoded.Total != report.Total {
+		t.Fatalf("report is not a valid json: %v", err)
+	}
+}
+
+func TestHighlightKeepsNumberFormat(t *testing.T) {
+	register := newTestRegisterer(t)
+	dateStyle, err := register.file.NewStyle(&excelize.Style{NumFmt: 14})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = register.file.SetCellStyle("Sheet1", "A2", "A2", dateStyle); err != nil {
+		t.Fatal(err)
+	}
+
+	register.WithSeverity(SeverityInfo).RegisterCommentByPosition("информация", 1, 2)
+	register.RegisterCommentByPosition("ошибка", 1, 2)
+	if !register.HasErrors() {
+		t.Fatal("error finding is lost")
+	}
+
+	reopened, err := excelize.OpenReader(bytes.NewReader(register.GetFileBytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	styleID, err := reopened.GetCellStyle("Sheet1", "A2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	xf := reopened.Styles.CellXfs.Xf[styleID]
+	if xf.NumFmtID == nil || *xf.NumFmtID != 14 {
+		t.Fatalf("number format is lost: %v", xf.NumFmtID)
+	}
+	fill := reopened.Styles.Fills.Fill[*xf.FillID]
+	if fill.PatternFill == nil || fill.PatternFill.FgColor == nil || fill.PatternFill.FgColor.RGB != "FFFFC7CE" {
+		t.Fatalf("cell is not highlighted as error: %+v", fill.PatternFill)
+	}
+}
+
+func TestSummarySheet(t *testing.T) {
+	register := newTestRegisterer(t)
+	WithSummarySheet()(register)
+
+	register.ForJob("sku").RegisterCommentByPosition("Плохой СКУ", 1, 2)
+	register.ForJob("sku").WithSeverity(SeverityInfo).RegisterCommentBySheet("лист проверен")
+	register.GetFileBytes()
+	reopened, err := excelize.OpenReader(bytes.NewReader(register.GetFileBytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sheets := reopened.GetSheetList(); len(sheets) != 2 || sheets[1] != SummarySheet {
+		t.Fatalf("unexpected sheets: %v", sheets)
+	}
+
+	rows, err := reopened.GetRows(SummarySheet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rows[1][1] != "2" || rows[4][0] != "error" || rows[4][1] != "1" || rows[9][0] != "sku" || rows[9][1] != "2" {
+		t.Fatalf("unexpected summary: %v", rows)
+	}
+	linked := rows[len(rows)-1]
+	if linked[1] != "A2" || linked[5] != "Плохой СКУ" {
+		t.Fatalf("unexpected finding row: %v", linked)
+	}
+	exists, link, err := reopened.GetCellHyperLink(SummarySheet, "B"+strconv.Itoa(len(rows)))
+	if err != nil || !exists || link != "'Sheet1'!A2" {
+		t.Fatalf("unexpected hyperlink %q: %v", link, err)
+	}
+}
+
+func TestDecoderFindings(t *testing.T) {
+	register := newTestRegisterer(t)
+	sheet := register.file.GetSheetName(0)
+	// комментарий, который был в файле до валидации, замечанием не считается
+	if err := register.file.AddComment(sheet, "B1", `{"author":"КМ","text":"заполнить до пятницы"}`); err != nil {
+		t.Fatal(err)
+	}
+	before := bookComments(register.file)
+
+	// так пишет декодер по тегам xlsx-validation
+	goxlsx.NewValidationRegister(register.file).RegisterByPosition(sheet, "Поле обязательно", 1, 2)
+	register.addDecoderFindings(before)
+
+	findings := register.Findings()
+	if len(findings) != 1 {
+		t.Fatalf("expected one decoder finding, got %+v", findings)
+	}
+	finding := findings[0]
+	if finding.JobID != "" || finding.Cell != "A2" || finding.Severity != SeverityError || finding.Message != "Поле обязательно" {
+		t.Fatalf("unexpected decoder finding %+v", finding)
+	}
+	if !register.HasErrors() {
+		t.Fatal("decoder findings must count as errors")
+	}
+}
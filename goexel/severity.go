@@ -0,0 +1,132 @@
+package goexel
+
+import (
+	"context"
+
+	"github.com/xuri/excelize/v2"
+	"gitlab.ozon.ru/platform/tracer-go/logger"
+)
+
+// DefaultSeverityFills - цвета заливки ячеек с замечаниями
+var DefaultSeverityFills = map[Severity]string{
+	SeverityError:   "#FFC7CE",
+	SeverityWarning: "#FFEB9C",
+	SeverityInfo:    "#DDEBF7",
+}
+
+// rank - чем серьезнее, тем больше, ячейку красим по самому серьезному замечанию
+func (s Severity) rank() int {
+	switch s {
+	case SeverityError:
+		return 3
+	case SeverityWarning:
+		return 2
+	case SeverityInfo:
+		return 1
+	}
+	return 0
+}
+
+// WithSeverity - копия регистратора, все замечания которой получают severity
+// без нее комментарии считаются ошибками, а записи в ячейки - предупреждениями
+func (f *FileCellRegisterer) WithSeverity(severity Severity) *FileCellRegisterer {
+	if f == nil {
+		return nil
+	}
+	res := *f
+	res.severity = severity
+	return &res
+}
+
+func (f FileCellRegisterer) severityOr(def Severity) Severity {
+	if f.severity != "" {
+		return f.severity
+	}
+	return def
+}
+
+// HasErrors - есть ли хоть одно замечание уровня error
+func (f FileCellRegisterer) HasErrors() bool {
+	f.findings.mu.Lock()
+	defer f.findings.mu.Unlock()
+	for _, finding := range f.findings.list {
+		if finding.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// WithSeverityFills - опция с цветами заливки по серьезности, пустая мапа отключает подсветку
+func WithSeverityFills(fills map[Severity]string) opt {
+	return func(f *FileCellRegisterer) {
+		f.fills = fills
+	}
+}
+
+// highlightCells - заливает ячейки с замечаниями цветом самого серьезного из них
+func (f FileCellRegisterer) highlightCells(ctx context.Context) {
+	if len(f.fills) == 0 {
+		return
+	}
+
+	worst := make(map[sheetCell]Severity)
+	for _, finding := range f.Findings() {
+		if finding.Cell == "" {
+			continue
+		}
+		key := sheetCell{sheet: finding.Sheet, cell: finding.Cell}
+		if finding.Severity.rank() > worst[key].rank() {
+			worst[key] = finding.Severity
+		}
+	}
+
+	for key, severity := range worst {
+		color, exists := f.fills[severity]
+		if !exists {
+			continue
+		}
+		base, err := f.file.GetCellStyle(key.sheet, key.cell)
+		if err != nil {
+			logger.Errorf(ctx, "failed to get cell style: %v", err)
+			continue
+		}
+		style, err := f.fillStyle(color, base)
+		if err != nil {
+			logger.Errorf(ctx, "failed to create fill style: %v", err)
+			continue
+		}
+		if err = f.file.SetCellStyle(key.sheet, key.cell, key.cell, style); err != nil {
+			logger.Errorf(ctx, "failed to set cell style: %v", err)
+		}
+	}
+}
+
+// fillStyle - стиль с заливкой, который сохраняет числовой формат исходной ячейки, иначе даты превратятся в числа
+func (f FileCellRegisterer) fillStyle(color string, base int) (int, error) {
+	style := numFmtStyle(f.file, base)
+	style.Font = defaultCellStyle.Font
+	style.Fill = excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{color}}
+	return f.file.NewStyle(style)
+}
+
+// numFmtStyle - стиль, в котором из стиля base книги book только числовой формат
+func numFmtStyle(book *excelize.File, base int) *excelize.Style {
+	style := &excelize.Style{}
+	styles := book.Styles
+	if styles != nil && styles.CellXfs != nil && base < len(styles.CellXfs.Xf) && styles.CellXfs.Xf[base].NumFmtID != nil {
+		numFmt := *styles.CellXfs.Xf[base].NumFmtID
+		style.NumFmt = numFmt
+		// пользовательские форматы начинаются со 164 и лежат в самом файле
+		if numFmt >= 164 && styles.NumFmts != nil {
+			for _, custom := range styles.NumFmts.NumFmt {
+				if custom.NumFmtID == numFmt {
+					code := custom.FormatCode
+					style.CustomNumFmt = &code
+					break
+				}
+			}
+		}
+	}
+	return style
+}
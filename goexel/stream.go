@@ -0,0 +1,304 @@
+package goexel
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/xuri/excelize/v2"
+	goxlsx "gitlab.ozon.ru/express/platform/lib/go-xlsx"
+)
+
+// streamChunk - сколько строк потокового файла декодируем за раз
+const streamChunk = 1024
+
+// streamBuffer - сколько пачек может ждать джобу, дальше чтение файла ждет самую медленную
+const streamBuffer = 2
+
+// Streamer - потоковый файл без параметра типа, чтобы платформа могла им управлять
+type Streamer interface {
+	// Streaming - строки не декодированы в Table и придут пачками через Subscribe
+	Streaming() bool
+	// Materialize - декодирует все строки в Table, для джоб, которым нужна вся таблица
+	Materialize() error
+	// Expect - заводит подписки джобам, вызывать до их запуска
+	Expect(jobIDs []string)
+	// Stream - читает лист итератором excelize и раздает пачки подписчикам по мере чтения
+	Stream(ctx context.Context) error
+	// Release - джоба закончилась, больше ее не ждем
+	Release(jobID string)
+}
+
+// GetStreamerFromContext - файл из SetFileContext, nil если его нет
+func GetStreamerFromContext(ctx context.Context) Streamer {
+	streamer, _ := ctx.Value(streamerKey).(Streamer)
+	return streamer
+}
+
+// Chunk - очередная пачка строк потокового файла
+type Chunk[T any] struct {
+	Rows []*T
+	// From - индекс первой строки пачки в таблице
+	From int
+	// Offset - пачка декодируется отдельной книгой, где ее строки идут сразу за шапкой,
+	// поэтому позиции в значениях строк сдвинуты на Offset, см WithRowOffset
+	Offset int
+}
+
+type stream[T any] struct {
+	sheet string
+	// last - номер последней непустой строки листа
+	last int
+	len  int
+
+	mu      sync.Mutex
+	subs    map[string]*streamSub[T]
+	started bool
+	err     error
+}
+
+type streamSub[T any] struct {
+	ch   chan Chunk[T]
+	done chan struct{}
+	once sync.Once
+}
+
+// NewStreamFile - файл, строки которого не декодируются целиком, а читаются пачками во время валидации.
+// Таблица из нескольких листов и пайплайн с джобами, которым нужна вся таблица, все равно декодируются целиком
+func NewStreamFile[T any](file []byte, opts ...opt) (*File[T], error) {
+	res, err := openFile[T](file, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if len(res.Sheets) > 1 {
+		return res, res.Materialize()
+	}
+
+	last, err := lastRow(res.book, res.Sheets[0])
+	if err != nil {
+		return nil, err
+	}
+	res.stream = &stream[T]{
+		sheet: res.Sheets[0],
+		last:  last,
+		len:   last - 1,
+		subs:  make(map[string]*streamSub[T]),
+	}
+	if res.stream.len < 0 {
+		res.stream.len = 0
+	}
+	return res, nil
+}
+
+// lastRow - номер последней непустой строки, пустые хвосты с форматированием декодер тоже не читает.
+// Это лишний проход по листу без декодирования, зато Len известен до запуска: по нему считаются прогресс и пропуск строк по дедлайну
+func lastRow(book *excelize.File, sheet string) (int, error) {
+	rows, err := book.Rows(sheet)
+	if err != nil {
+		return 0, errors.Wrap(err, "Ошибка чтения файла")
+	}
+	defer rows.Close()
+
+	last := 0
+	for i := 1; rows.Next(); i++ {
+		columns, err := rows.Columns()
+		if err != nil {
+			return 0, errors.Wrap(err, "Ошибка чтения файла")
+		}
+		if strings.TrimSpace(strings.Join(columns, "")) != "" {
+			last = i
+		}
+	}
+	return last, rows.Error()
+}
+
+func (f *File[T]) Streaming() bool {
+	return f.stream != nil && f.Table == nil
+}
+
+func (f *File[T]) Expect(jobIDs []string) {
+	f.stream.mu.Lock()
+	defer f.stream.mu.Unlock()
+	for _, jobID := range jobIDs {
+		f.stream.subs[jobID] = &streamSub[T]{
+			ch:   make(chan Chunk[T], streamBuffer),
+			done: make(chan struct{}),
+		}
+	}
+}
+
+// Subscribe - пачки строк для джобы, канал закрывается в конце листа, ошибка чтения в StreamErr
+func (f *File[T]) Subscribe(jobID string) (<-chan Chunk[T], bool) {
+	f.stream.mu.Lock()
+	defer f.stream.mu.Unlock()
+	sub, exists := f.stream.subs[jobID]
+	if !exists {
+		return nil, false
+	}
+	return sub.ch, true
+}
+
+func (f *File[T]) Release(jobID string) {
+	f.stream.mu.Lock()
+	sub, exists := f.stream.subs[jobID]
+	f.stream.mu.Unlock()
+	if exists {
+		sub.once.Do(func() { close(sub.done) })
+	}
+}
+
+// StreamErr - почему чтение листа закончилось раньше времени
+func (f *File[T]) StreamErr() error {
+	f.stream.mu.Lock()
+	defer f.stream.mu.Unlock()
+	return f.stream.err
+}
+
+func (f *File[T]) Stream(ctx context.Context) (err error) {
+	s := f.stream
+	s.mu.Lock()
+	if s.started {
+		s.mu.Unlock()
+		return errors.New("Строки файла уже прочитаны потоком")
+	}
+	s.started = true
+	subs := make([]*streamSub[T], 0, len(s.subs))
+	for _, sub := range s.subs {
+		subs = append(subs, sub)
+	}
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		s.err = err
+		s.mu.Unlock()
+		for _, sub := range subs {
+			close(sub.ch)
+		}
+	}()
+
+	// лист читаем под тем же мьютексом, что и регистратор, он тоже лезет в книгу
+	mu := f.CellRegister.commMu
+	mu.Lock()
+	rows, err := f.book.Rows(s.sheet)
+	mu.Unlock()
+	if err != nil {
+		return errors.Wrap(err, "Ошибка чтения файла")
+	}
+	defer rows.Close()
+
+	var (
+		header []string
+		chunk  = make([][]string, 0, streamChunk)
+		from   int
+	)
+	for row := 1; row <= s.last; row++ {
+		var columns []string
+		mu.Lock()
+		next := rows.Next()
+		if next {
+			columns, err = rows.Columns()
+		}
+		mu.Unlock()
+		if !next {
+			break
+		}
+		if err != nil {
+			return errors.Wrap(err, "Ошибка чтения файла")
+		}
+		if row == 1 {
+			header = columns
+			continue
+		}
+
+		chunk = append(chunk, columns)
+		if len(chunk) < streamChunk && row < s.last {
+			continue
+		}
+		first := row - len(chunk) + 1
+		decoded, err := f.decodeChunk(header, chunk, first)
+		if err != nil {
+			return err
+		}
+		res := Chunk[T]{Rows: decoded, From: from, Offset: first - 2}
+		for _, sub := range subs {
+			select {
+			case sub.ch <- res:
+			case <-sub.done:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		from += len(decoded)
+		chunk = make([][]string, 0, streamChunk)
+	}
+	return rows.Error()
+}
+
+// decodeChunk - декодирует пачку строк отдельной книгой из шапки и пачки, first - номер первой строки пачки в листе.
+// Замечания декодера переносим в исходный файл на настоящие позиции, шапку проверяем только с первой пачкой
+func (f *File[T]) decodeChunk(header []string, chunk [][]string, first int) ([]*T, error) {
+	sheet := f.stream.sheet
+	book := excelize.NewFile()
+	book.SetSheetName(book.GetSheetName(0), sheet)
+	if err := book.SetSheetRow(sheet, "A1", &header); err != nil {
+		return nil, errors.Wrap(err, "Ошибка чтения файла")
+	}
+	for i := range chunk {
+		if err := book.SetSheetRow(sheet, "A"+strconv.Itoa(i+2), &chunk[i]); err != nil {
+			return nil, errors.Wrap(err, "Ошибка чтения файла")
+		}
+	}
+
+	decoder := goxlsx.NewDecoder(book)
+	decoder.AddRegister(goxlsx.NewValidationRegister(book))
+	res := make([]*T, 0, len(chunk))
+	decoder.Decode(&res)
+
+	// в отчет замечания попадают после записи комментариев, addFinding сам берет commMu
+	var findings []Finding
+	f.CellRegister.commMu.Lock()
+	for _, comment := range book.GetComments()[sheet] {
+		col, row, err := excelize.CellNameToCoordinates(comment.Ref)
+		if err != nil || (row == 1 && first > 2) {
+			continue
+		}
+		if row > 1 {
+			row += first - 2
+		}
+		f.comments.RegisterByPosition(sheet, commentText(comment), col, row)
+		findings = append(findings, Finding{Column: col, Row: row, Message: commentText(comment)})
+	}
+	f.CellRegister.commMu.Unlock()
+
+	for _, finding := range findings {
+		f.CellRegister.addDecoderFinding(sheet, finding.Column, finding.Row, finding.Message)
+	}
+	return res, nil
+}
+
+// commentText - текст комментария без автора, excelize склеивает их при чтении
+func commentText(comment excelize.Comment) string {
+	text := strings.TrimSpace(strings.TrimPrefix(comment.Text, comment.Author))
+	return strings.TrimSpace(strings.TrimPrefix(text, ":"))
+}
+
+// WithRowOffset - копия регистратора для строк пачки потокового файла, сдвигает их позиции на offset
+func (f *FileCellRegisterer) WithRowOffset(offset int) *FileCellRegisterer {
+	if f == nil {
+		return nil
+	}
+	res := *f
+	res.rowOffset = offset
+	return &res
+}
+
+// row - позиция строки в исходном листе, шапка не сдвигается
+func (f FileCellRegisterer) row(row int) int {
+	if row > 1 {
+		return row + f.rowOffset
+	}
+	return row
+}
@@ -0,0 +1,104 @@
+package goexel
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+	"gitlab.ozon.ru/platform/tracer-go/logger"
+)
+
+// SummarySheet - имя листа с итогами, см WithSummarySheet
+const SummarySheet = "Итоги валидации"
+
+// WithSummarySheet - опция, которая при GetFileBytes добавляет в книгу лист с итогами:
+// количество замечаний по серьезности и джобам и таблицу замечаний со ссылками на ячейки
+func WithSummarySheet() opt {
+	return func(f *FileCellRegisterer) {
+		f.summary = true
+	}
+}
+
+var summarySeverities = []Severity{SeverityError, SeverityWarning, SeverityInfo}
+
+// writeSummary - пересоздает лист итогов, так что повторный GetFileBytes не плодит дубли
+func (f FileCellRegisterer) writeSummary(ctx context.Context) {
+	if !f.summary {
+		return
+	}
+	report := f.GetReport()
+
+	if f.file.GetSheetIndex(SummarySheet) >= 0 {
+		f.file.DeleteSheet(SummarySheet)
+	}
+	f.file.NewSheet(SummarySheet)
+
+	rows := [][]interface{}{
+		{SummarySheet},
+		{"Всего замечаний", report.Total},
+		{},
+		{"Серьезность", "Количество"},
+	}
+	for _, severity := range summarySeverities {
+		rows = append(rows, []interface{}{string(severity), report.BySeverity[severity]})
+	}
+
+	jobs := make([]string, 0, len(report.ByJob))
+	for job := range report.ByJob {
+		jobs = append(jobs, job)
+	}
+	sort.Strings(jobs)
+	rows = append(rows, []interface{}{}, []interface{}{"Джоба", "Количество"})
+	for _, job := range jobs {
+		rows = append(rows, []interface{}{jobName(job), report.ByJob[job]})
+	}
+
+	rows = append(rows, []interface{}{}, []interface{}{"Лист", "Ячейка", "Колонка", "Серьезность", "Джоба", "Замечание", "Значение"})
+	firstFinding := len(rows) + 1
+	for _, finding := range report.Findings {
+		rows = append(rows, []interface{}{
+			finding.Sheet, finding.Cell, finding.Header, string(finding.Severity), jobName(finding.JobID), finding.Message, finding.Value,
+		})
+	}
+
+	for i, row := range rows {
+		if len(row) == 0 {
+			continue
+		}
+		axis, _ := excelize.CoordinatesToCellName(1, i+1)
+		if err := f.file.SetSheetRow(SummarySheet, axis, &row); err != nil {
+			logger.Errorf(ctx, "failed to write summary row: %v", err)
+		}
+	}
+
+	for i, finding := range report.Findings {
+		if finding.Cell == "" {
+			continue
+		}
+		axis, _ := excelize.CoordinatesToCellName(2, firstFinding+i)
+		if err := f.file.SetCellHyperLink(SummarySheet, axis, cellLocation(finding.Sheet, finding.Cell), "Location"); err != nil {
+			logger.Errorf(ctx, "failed to set summary hyperlink: %v", err)
+		}
+	}
+
+	if err := f.file.SetColWidth(SummarySheet, "A", "A", 24); err != nil {
+		logger.Errorf(ctx, "failed to set summary width: %v", err)
+	}
+	if err := f.file.SetColWidth(SummarySheet, "F", "F", 60); err != nil {
+		logger.Errorf(ctx, "failed to set summary width: %v", err)
+	}
+}
+
+// jobName - замечания без джобы пишет сам декодер по тегам xlsx-validation, см addDecoderFindings
+func jobName(jobID string) string {
+	if jobID == "" {
+		return "декодер"
+	}
+	return jobID
+}
+
+// cellLocation - ссылка внутри книги, имя листа в кавычках, потому что в нем бывают пробелы
+func cellLocation(sheet, cell string) string {
+	return "'" + strings.ReplaceAll(sheet, "'", "''") + "'!" + cell
+}
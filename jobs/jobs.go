@@ -4,11 +4,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"reflect"
 	"sort"
 	"strings"
 	"time"
 
 	goxlsx "gitlab.ozon.ru/express/platform/lib/go-xlsx"
+	"gitlab.ozon.ru/platform/tracer-go/logger"
 	"gitlab.ozon.ru/validator/goexel"
 	"gitlab.ozon.ru/validator/platform"
 )
@@ -52,38 +54,97 @@ func (e Entry) GetItemID() int64 {
 	return e.ItemID.Value
 }
 
+// SkuCatalog - где проверяем, что СКУ существуют, реализации в пакете catalog
+type SkuCatalog interface {
+	// Lookup - какие из skus есть в каталоге
+	Lookup(ctx context.Context, skus []int64) (map[int64]struct{}, error)
+}
+
+// SkuChecker - есть ли СКУ строки в каталоге, каталог спрашивает один раз на файл,
+// если он недоступен - строки пропускает, а на лист пишет одно предупреждение
 type SkuChecker struct {
 	*platform.JobWrapper
+	platform.Produces[bool]
 
-	Exists map[int64]struct{}
+	Catalog SkuCatalog
 }
 
 func (j *SkuChecker) Run(ctx context.Context) (err error) {
 
-	checkerResChan := j.Dependencies["Валидный ли Ску"]
+	checkerResChan := platform.DepChan[bool](j.JobWrapper, "Валидный ли Ску")
+
+	// в каталог идем один раз за всеми СКУ таблицы, а не на каждую строку
+	existing, lookupErr := j.prefetch(ctx)
+	if lookupErr != nil {
+		logger.Errorf(ctx, "[%s]: %v", j.GetID(), lookupErr)
+	}
+	reported := false
 
 	return platform.RunByLine[Entry](ctx, j.JobWrapper, func(c context.Context, register *goexel.FileCellRegisterer, row *Entry) platform.JobResult {
-		checkerRes := checkerResChan.Recv(ctx)
-		if checkerRes.Err != nil {
-			return checkerRes
+		isValidSKU, err := checkerResChan.Recv(ctx)
+		if err != nil {
+			return platform.JobResult{Err: err}
 		}
-		if isValidSKU := checkerRes.Res.(bool); !isValidSKU {
+		if !isValidSKU {
 			register.RegisterCellValueByString([]string{"Знаю что 1."}, row.Comment)
 			return platform.JobResult{Err: platform.ErrSkipped}
 		}
 
-		_, exists := j.Exists[row.ItemID.Value]
+		// каталог недоступен - это не ошибка файла, строки пропускаем, а на лист пишем одно предупреждение
+		if lookupErr != nil {
+			if !reported {
+				register.OnSheet(row.ItemID.GetSheetName()).WithSeverity(goexel.SeverityWarning).RegisterCommentBySheet("Не удалось проверить СКУ в каталоге")
+				reported = true
+			}
+			return platform.JobResult{Err: platform.ErrSkipped}
+		}
+
+		_, exists := existing[row.ItemID.Value]
 		if !exists {
-			register.RegisterCellValueByString([]string{"СКУ НЕ В МАПЕ."}, row.Comment)
+			register.WithSeverity(goexel.SeverityError).RegisterCellValueByString([]string{"СКУ нет в каталоге."}, row.Comment)
 		}
 		return platform.JobResult{Res: exists}
 	})
 }
 
+// prefetch - все непустые СКУ таблицы одним запросом в каталог
+func (j *SkuChecker) prefetch(ctx context.Context) (map[int64]struct{}, error) {
+	file := goexel.GetFileFromContext[Entry](ctx)
+	seen := make(map[int64]struct{}, len(file.Table))
+	skus := make([]int64, 0, len(file.Table))
+	for _, row := range file.Table {
+		if row.ItemID.IsEmpty() || !row.ItemID.IsValid() {
+			continue
+		}
+		if _, exists := seen[row.ItemID.Value]; exists {
+			continue
+		}
+		seen[row.ItemID.Value] = struct{}{}
+		skus = append(skus, row.ItemID.Value)
+	}
+	if len(skus) == 0 {
+		return nil, nil
+	}
+	return j.Catalog.Lookup(platform.JobContext(ctx), skus)
+}
+
+// NeedsWholeTable - СКУ собираем по всей таблице до первой строки. SkuChecker в наборе джоб по умолчанию,
+// так что по умолчанию файл всегда читается целиком, а не потоком: поход в каталог на каждую строку дороже,
+// чем таблица в памяти. Потоком идут только запуски без него и без других джоб, которым нужна вся таблица
+func (j *SkuChecker) NeedsWholeTable() bool {
+	return true
+}
+
 func (j *SkuChecker) GetDepIDs() []platform.JobID {
 	return []platform.JobID{"Валидный ли Ску"}
 }
 
+func (j *SkuChecker) GetDepTypes() map[platform.JobID]reflect.Type {
+	return map[platform.JobID]reflect.Type{
+		"Валидный ли Ску": platform.TypeOf[bool](),
+	}
+}
+
 func (j *SkuChecker) GetID() platform.JobID {
 	return "СКУ В МАПЕ ЧЕКЕР"
 }
@@ -95,7 +156,7 @@ func (j *SkuChecker) GetType() platform.JobType {
 func (j *SkuChecker) Create() platform.Job {
 	return &SkuChecker{
 		JobWrapper: j.JobWrapper.Create(),
-		Exists:     j.Exists,
+		Catalog:    j.Catalog,
 	}
 }
 
@@ -103,6 +164,7 @@ func (j *SkuChecker) Create() platform.Job {
 
 type IsSkuValid struct {
 	*platform.JobWrapper
+	platform.Produces[bool]
 }
 
 func (j *IsSkuValid) Run(ctx context.Context) (err error) {
@@ -111,7 +173,7 @@ func (j *IsSkuValid) Run(ctx context.Context) (err error) {
 		time.Sleep(time.Millisecond)
 		isEmpty := row.ItemID.IsEmpty() || row.ItemID.Value == 1
 		if isEmpty {
-			register.RegisterCellValueByString([]string{"Пустой Ску."}, row.Comment)
+			register.WithSeverity(goexel.SeverityError).RegisterCellValueByString([]string{"Пустой Ску."}, row.Comment)
 		}
 		return platform.JobResult{
 			Res: !isEmpty,
@@ -131,6 +193,18 @@ func (j *IsSkuValid) GetType() platform.JobType {
 	return platform.Common
 }
 
+func (j *IsSkuValid) IsRowIndependent() bool {
+	return true
+}
+
+func (j *IsSkuValid) CacheVersion() string {
+	return "1"
+}
+
+func (j *IsSkuValid) CacheFields() []string {
+	return []string{"ItemID"}
+}
+
 func (j *IsSkuValid) Create() platform.Job {
 	return &IsSkuValid{
 		JobWrapper: j.JobWrapper.Create(),
@@ -141,12 +215,14 @@ func (j *IsSkuValid) Create() platform.Job {
 
 type DataValidation struct {
 	*platform.JobWrapper
+	platform.Produces[bool]
 }
 
 func (j *DataValidation) Run(ctx context.Context) (err error) {
 
 	return platform.RunByLine[Entry](ctx, j.JobWrapper, func(c context.Context, register *goexel.FileCellRegisterer, row *Entry) platform.JobResult {
 		rowNumber := row.PromoName.GetRowNumber()
+		register = register.OnSheet(row.PromoName.GetSheetName())
 
 		if !row.PromoDateFrom.IsValid() {
 			register.RegisterCommentByRow(fmt.Sprintf("Невалидное поле Дата начала %s", row.PromoDateFrom.Value), rowNumber)
@@ -179,6 +255,18 @@ func (j *DataValidation) GetType() platform.JobType {
 	return platform.Common
 }
 
+func (j *DataValidation) IsRowIndependent() bool {
+	return true
+}
+
+func (j *DataValidation) CacheVersion() string {
+	return "1"
+}
+
+func (j *DataValidation) CacheFields() []string {
+	return []string{"PromoDateFrom", "PromoDateTo"}
+}
+
 func (j *DataValidation) Create() platform.Job {
 	return &DataValidation{
 		JobWrapper: j.JobWrapper.Create(),
@@ -189,27 +277,28 @@ func (j *DataValidation) Create() platform.Job {
 
 type FunValidation struct {
 	*platform.JobWrapper
+	platform.Produces[bool]
 }
 
 func (j *FunValidation) Run(ctx context.Context) (err error) {
 
-	isValidSkuChan := j.Dependencies["Валидный ли Ску"]
-	dataChekerChan := j.Dependencies["Влидация дат начала и конца промо акции"]
+	isValidSkuChan := platform.DepChan[bool](j.JobWrapper, "Валидный ли Ску")
+	dataChekerChan := platform.DepChan[bool](j.JobWrapper, "Влидация дат начала и конца промо акции")
 
 	return platform.RunByLine[Entry](ctx, j.JobWrapper, func(c context.Context, register *goexel.FileCellRegisterer, row *Entry) platform.JobResult {
-		isValidSkuRes := isValidSkuChan.Recv(ctx)
-		if isValidSkuRes.Err != nil {
-			return isValidSkuRes
+		isValidSku, err := isValidSkuChan.Recv(ctx)
+		if err != nil {
+			return platform.JobResult{Err: err}
 		}
-		isValidSku := isValidSkuRes.Res.(bool)
 
 		if isValidSku {
-			isVaidDataRes := dataChekerChan.Recv(ctx)
-			if isVaidDataRes.Err != nil {
-				return isVaidDataRes
+			// даты читаем только для валидных ску, поэтому по ключу строки, иначе результаты разъедутся
+			isValidData, err := dataChekerChan.RecvFor(ctx, platform.RowIndex(c))
+			if err != nil {
+				return platform.JobResult{Err: err}
 			}
-			if isValidData := isVaidDataRes.Res.(bool); isValidData {
-				register.RegisterCellValueByString([]string{"Фановая валидация нашла валидную дату)."}, row.SoftErrors)
+			if isValidData {
+				register.WithSeverity(goexel.SeverityInfo).RegisterCellValueByString([]string{"Фановая валидация нашла валидную дату)."}, row.SoftErrors)
 				return platform.JobResult{
 					Res: true,
 				}
@@ -226,6 +315,13 @@ func (j *FunValidation) GetDepIDs() []platform.JobID {
 	return []platform.JobID{"Влидация дат начала и конца промо акции", "Валидный ли Ску"}
 }
 
+func (j *FunValidation) GetDepTypes() map[platform.JobID]reflect.Type {
+	return map[platform.JobID]reflect.Type{
+		"Влидация дат начала и конца промо акции": platform.TypeOf[bool](),
+		"Валидный ли Ску":                         platform.TypeOf[bool](),
+	}
+}
+
 func (j *FunValidation) GetID() platform.JobID {
 	return "Проверяем двойные зависимости"
 }
@@ -283,21 +379,21 @@ type BatchVolumeValidation struct {
 func (j *BatchVolumeValidation) Run(ctx context.Context) (err error) {
 
 	var (
-		clusterChan     = j.Dependencies["Валидация кластеров"]
+		clusterChan     = platform.DepChan[string](j.JobWrapper, "Валидация кластеров")
 		clusterVolumes  = make(map[string]int32, 10)
 		wrongPrivileged = make([]string, 0, 2)
 	)
 	return platform.RunByItemBatch(ctx, j.JobWrapper, func(c context.Context, register *goexel.FileCellRegisterer, rows []*Entry) platform.JobResult {
-		for _, row := range rows {
-			jobResult := clusterChan.Recv(ctx)
-			if jobResult.Err != nil {
-				if errors.Is(jobResult.Err, platform.ErrFatal) {
-					return jobResult
+		first := platform.RowIndex(c)
+		for k, row := range rows {
+			cluster, err := clusterChan.RecvFor(ctx, first+k)
+			if err != nil {
+				if errors.Is(err, platform.ErrFatal) {
+					return platform.JobResult{Err: err}
 				}
 				continue
 			}
 
-			cluster := jobResult.Res.(string)
 			clusterVolumes[cluster] += row.Volume.Value
 		}
 
@@ -340,6 +436,12 @@ func (j *BatchVolumeValidation) GetDepIDs() []platform.JobID {
 	return []platform.JobID{"Сортируем по скухам", "Валидация кластеров"}
 }
 
+func (j *BatchVolumeValidation) GetDepTypes() map[platform.JobID]reflect.Type {
+	return map[platform.JobID]reflect.Type{
+		"Валидация кластеров": platform.TypeOf[string](),
+	}
+}
+
 func (j *BatchVolumeValidation) GetID() platform.JobID {
 	return "Относительный объем"
 }
@@ -348,6 +450,10 @@ func (j *BatchVolumeValidation) GetType() platform.JobType {
 	return platform.Common
 }
 
+func (j *BatchVolumeValidation) GetGranularity() platform.Granularity {
+	return platform.ByItemBatch
+}
+
 func (j *BatchVolumeValidation) Create() platform.Job {
 	return &BatchVolumeValidation{
 		PrivilegedClusters: j.PrivilegedClusters,
@@ -359,6 +465,7 @@ func (j *BatchVolumeValidation) Create() platform.Job {
 
 type IsClusterValid struct {
 	*platform.JobWrapper
+	platform.Produces[string]
 
 	ValidClusters map[string]struct{}
 }
@@ -393,6 +500,19 @@ func (j *IsClusterValid) GetType() platform.JobType {
 	return platform.Common
 }
 
+func (j *IsClusterValid) IsRowIndependent() bool {
+	return true
+}
+
+// CacheVersion - от списка кластеров зависит результат, поэтому он тоже в версии
+func (j *IsClusterValid) CacheVersion() string {
+	return platform.ConfigVersion("1", j.ValidClusters)
+}
+
+func (j *IsClusterValid) CacheFields() []string {
+	return []string{"WhcClusterName"}
+}
+
 func (j *IsClusterValid) Create() platform.Job {
 	return &IsClusterValid{
 		JobWrapper:    j.JobWrapper.Create(),
@@ -0,0 +1,136 @@
+package jobs_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"gitlab.ozon.ru/validator/broadcaster"
+	"gitlab.ozon.ru/validator/goexel"
+	"gitlab.ozon.ru/validator/goexel/goexeltest"
+	"gitlab.ozon.ru/validator/jobs"
+	"gitlab.ozon.ru/validator/platform"
+)
+
+// fakeCatalog - в каталоге только СКУ 5, считает походы
+type fakeCatalog struct {
+	mu    sync.Mutex
+	calls int
+	err   error
+}
+
+func (c *fakeCatalog) Lookup(_ context.Context, skus []int64) (map[int64]struct{}, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls++
+	if c.err != nil {
+		return nil, c.err
+	}
+	res := make(map[int64]struct{})
+	for _, sku := range skus {
+		if sku == 5 {
+			res[sku] = struct{}{}
+		}
+	}
+	return res, nil
+}
+
+func newEntryFile(t *testing.T, skus ...string) *goexel.File[jobs.Entry] {
+	t.Helper()
+	file, err := goexel.NewFile[jobs.Entry](goexeltest.Bytes(t, goexeltest.SkuBook(t, skus...)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+// runSkuChecker - результаты SkuChecker по строкам и проверенный файл
+func runSkuChecker(t *testing.T, catalog jobs.SkuCatalog, skus ...string) ([]platform.JobResult, *goexel.File[jobs.Entry]) {
+	t.Helper()
+	plat := platform.NewPlatform(5*time.Second, platform.JobPool{JobMap: map[platform.JobID]platform.Job{}})
+	_ = plat.AddJob(&jobs.IsSkuValid{JobWrapper: &platform.JobWrapper{ResChan: &broadcaster.Broadcaster[platform.JobResult]{}}})
+	_ = plat.AddJob(&jobs.SkuChecker{
+		JobWrapper: &platform.JobWrapper{ResChan: broadcaster.NewBroadcaster[platform.JobResult](broadcaster.WithReplay(0))},
+		Catalog:    catalog,
+	})
+
+	file := newEntryFile(t, skus...)
+	ctx := goexel.SetFileContext(context.Background(), file)
+	pipe, err := plat.NewPipeline(ctx, []platform.JobID{"СКУ В МАПЕ ЧЕКЕР"}, file.Len())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = plat.StartPipeline(ctx, pipe); err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := plat.SubscribeJob(pipe.GetID(), "СКУ В МАПЕ ЧЕКЕР")
+	if err != nil {
+		t.Fatal(err)
+	}
+	res := make([]platform.JobResult, len(skus))
+	for r := range results {
+		res[r.Row] = r
+	}
+	return res, file
+}
+
+func TestSkuChecker(t *testing.T) {
+	catalog := &fakeCatalog{}
+	// 1 - невалидный СКУ, его отсекает "Валидный ли Ску"
+	res, file := runSkuChecker(t, catalog, "5", "7", "1", "5")
+	if catalog.calls != 1 {
+		t.Fatalf("expected one catalog lookup per file, got %d", catalog.calls)
+	}
+	for i, expected := range []interface{}{true, false, nil, true} {
+		if expected == nil {
+			if !errors.Is(res[i].Err, platform.ErrSkipped) {
+				t.Fatalf("row %d: expected a skip, got %+v", i, res[i])
+			}
+			continue
+		}
+		if res[i].Err != nil || res[i].Res != expected {
+			t.Fatalf("row %d: expected %v, got %+v", i, expected, res[i])
+		}
+	}
+
+	var missing []goexel.Finding
+	for _, finding := range file.CellRegister.Findings() {
+		if finding.Message == "СКУ нет в каталоге." {
+			missing = append(missing, finding)
+		}
+	}
+	if len(missing) != 1 || missing[0].Row != 3 || missing[0].Severity != goexel.SeverityError {
+		t.Fatalf("expected one missing sku error on row 3, got %+v", missing)
+	}
+	// по HasErrors CLI выходит с ошибкой, а watch кладет файл в failed
+	if !file.CellRegister.HasErrors() {
+		t.Fatal("a missing sku must make the file invalid")
+	}
+}
+
+func TestSkuCheckerCatalogDown(t *testing.T) {
+	catalog := &fakeCatalog{err: errors.New("catalog is down")}
+	res, file := runSkuChecker(t, catalog, "5", "7", "5")
+	if catalog.calls != 1 {
+		t.Fatalf("expected one catalog lookup per file, got %d", catalog.calls)
+	}
+	// недоступный каталог - не ошибка файла, строки пропускаются
+	for i, r := range res {
+		if !errors.Is(r.Err, platform.ErrSkipped) {
+			t.Fatalf("row %d: expected a skip, got %+v", i, r)
+		}
+	}
+
+	var warnings []goexel.Finding
+	for _, finding := range file.CellRegister.Findings() {
+		if finding.JobID == "СКУ В МАПЕ ЧЕКЕР" {
+			warnings = append(warnings, finding)
+		}
+	}
+	if len(warnings) != 1 || warnings[0].Severity != goexel.SeverityWarning || warnings[0].Row != 0 {
+		t.Fatalf("expected one sheet warning, got %+v", warnings)
+	}
+}
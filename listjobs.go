@@ -0,0 +1,44 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"gitlab.ozon.ru/validator/platform"
+)
+
+// listJobs - все джобы валидатора с типом и зависимостями: list-jobs [/path/to/rules.yaml]
+// звездочкой помечены джобы, которые запускаются по умолчанию
+func listJobs(args []string) {
+	var rulesPath string
+	if len(args) > 0 {
+		rulesPath = args[0]
+	}
+	plat, defaultJobs := newPlatform(rulesPath, nil)
+
+	defaults := make(map[platform.JobID]struct{}, len(defaultJobs))
+	for _, jobID := range defaultJobs {
+		defaults[jobID] = struct{}{}
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "JOB\tTYPE\tDEPENDS ON\tDEFAULT")
+	for _, job := range plat.Jobs() {
+		deps := make([]string, 0, len(job.GetDepIDs()))
+		for _, dep := range job.GetDepIDs() {
+			deps = append(deps, string(dep))
+		}
+		dependsOn := strings.Join(deps, ", ")
+		if dependsOn == "" {
+			dependsOn = "-"
+		}
+		var isDefault string
+		if _, exists := defaults[job.GetID()]; exists {
+			isDefault = "*"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", job.GetID(), job.GetType(), dependsOn, isDefault)
+	}
+	_ = w.Flush()
+}
@@ -2,55 +2,473 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
-	"io"
 	"log"
 	"math"
+	"net/url"
 	"os"
+	"path"
 	"strings"
 	"time"
 
 	"github.com/fatih/color"
+	"github.com/go-redis/redis/v8"
+	"gitlab.ozon.ru/platform/errors"
 	"gitlab.ozon.ru/platform/tracer-go/logger"
 	"gitlab.ozon.ru/validator/broadcaster"
+	"gitlab.ozon.ru/validator/cache"
+	"gitlab.ozon.ru/validator/catalog"
 	"gitlab.ozon.ru/validator/goexel"
 	"gitlab.ozon.ru/validator/jobs"
 	"gitlab.ozon.ru/validator/platform"
+	"gitlab.ozon.ru/validator/rules"
 )
 
 var boundedStrLayout = "-----------------------\n%s\n--------------------------------\n"
 
+// batchResultBuffer - сколько результатов может копиться для батчевой джобы, пока она собирает пачку
+const batchResultBuffer = 256
+
+// formatJSON - вместо размеченного файла сохраняется только отчет
+const formatJSON goexel.Format = "json"
+
 func main() {
 	log.Default().SetFlags(log.Ltime)
-	if len(os.Args) < 2 {
-		log.Fatalf("usage: %s ", color.HiMagentaString("/path/to/file.xlsx"))
+	args := os.Args[1:]
+	if len(args) > 0 {
+		switch args[0] {
+		case "serve":
+			useLocalConfig()
+			serve(args[1:])
+			return
+		case "list-jobs":
+			useLocalConfig()
+			listJobs(args[1:])
+			return
+		case "plan":
+			useLocalConfig()
+			plan(args[1:])
+			return
+		case "watch":
+			useLocalConfig()
+			watch(args[1:])
+			return
+		}
 	}
-	os.Args = append(os.Args, "--local-config-enabled")
+	validate(args)
+}
 
-	filepath := os.Args[1]
-	//nolint:gosec
-	validationFile, err := os.Open(filepath)
-	if err != nil {
-		log.Fatalf("failed to open %s: %s", filepath, color.RedString(err.Error()))
+// useLocalConfig - платформенные библиотеки берут конфиг из локального файла, а не из окружения.
+// Свои аргументы к этому моменту уже разобраны, им платформенные флаги не мешают и наоборот
+func useLocalConfig() {
+	os.Args = append(os.Args[:1], "--local-config-enabled")
+}
+
+type cliOptions struct {
+	jobs        string
+	excludeJobs string
+	out         string
+	format      string
+	sheet       string
+	timeout     time.Duration
+	noProgress  bool
+	edgeStats   bool
+	parallel    int
+	// outDir - папка для результатов вместо папки исходного файла
+	outDir string
+	// cacheDir - папка кеша результатов строк, пусто - без кеша
+	cacheDir string
+	// skuCatalog - файл, redis://host:port/key или http(s) адрес каталога СКУ, пусто - встроенный список
+	skuCatalog  string
+	localConfig bool
+}
+
+func usage(fs *flag.FlagSet) func() {
+	return func() {
+		fmt.Fprintf(fs.Output(), "usage: %s [flags] %s [%s]\n       serve [--sku-catalog ...] [%s] [%s] [%s]\n       list-jobs [%s]\n       plan [--jobs ...] [--exclude-jobs ...] [--format text|dot|mermaid] [%s]\n       watch [--out /path/to/results] [--poll 2s] %s [%s]\n\nflags:\n",
+			path.Base(os.Args[0]),
+			color.HiMagentaString("/path/to/file.xlsx|csv|tsv|dir|glob"), color.HiMagentaString("/path/to/rules.yaml"),
+			color.HiMagentaString(":8080"), color.HiMagentaString("/path/to/rules.yaml"), color.HiMagentaString(":8081"),
+			color.HiMagentaString("/path/to/rules.yaml"), color.HiMagentaString("/path/to/rules.yaml"),
+			color.HiMagentaString("/path/to/inbox"), color.HiMagentaString("/path/to/rules.yaml"))
+		fs.PrintDefaults()
+	}
+}
+
+// validate - валидация файла, а если передана папка или glob, то всех файлов в ней: validate [flags] file [rules.yaml]
+func validate(args []string) {
+	var opts cliOptions
+	fs := flag.NewFlagSet(path.Base(os.Args[0]), flag.ExitOnError)
+	fs.StringVar(&opts.jobs, "jobs", "", "comma separated jobs to run instead of the default ones")
+	fs.StringVar(&opts.excludeJobs, "exclude-jobs", "", "comma separated jobs to skip")
+	fs.StringVar(&opts.out, "out", "", "output path, next to the input file by default; summary path for a directory")
+	fs.StringVar(&opts.format, "format", "", "output format: xlsx|csv|tsv|json, the input format by default")
+	fs.StringVar(&opts.sheet, "sheet", "", "validate only this sheet of the xlsx file")
+	fs.DurationVar(&opts.timeout, "timeout", time.Minute, "validation time limit, 0 - no limit")
+	fs.BoolVar(&opts.noProgress, "no-progress", false, "don't draw the progress bar")
+	fs.BoolVar(&opts.edgeStats, "edge-stats", false, "print how long jobs waited for their subscribers")
+	fs.IntVar(&opts.parallel, "parallel", 4, "how many files of a directory are validated at once")
+	fs.StringVar(&opts.cacheDir, "cache-dir", "", "cache row results and found skus here, so revalidating a corrected file recomputes only changed rows")
+	fs.StringVar(&opts.skuCatalog, "sku-catalog", "", skuCatalogUsage)
+	fs.BoolVar(&opts.localConfig, "local-config", true, "read platform config from the local file")
+	fs.Usage = usage(fs)
+	_ = fs.Parse(args)
+	if opts.localConfig {
+		useLocalConfig()
+	} else {
+		os.Args = os.Args[:1]
+	}
+
+	if fs.NArg() < 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	var rulesPath string
+	if fs.NArg() > 1 {
+		rulesPath = fs.Arg(1)
+	}
+
+	target := fs.Arg(0)
+	if opts.format != "" {
+		switch goexel.Format(strings.ToLower(opts.format)) {
+		case goexel.FormatXLSX, goexel.FormatCSV, goexel.FormatTSV, formatJSON:
+		default:
+			log.Fatalf("unknown output format %s", color.RedString(opts.format))
+		}
 	}
 	log.Printf(boundedStrLayout, color.YellowString("start app initialization"))
 	ctx := context.Background()
 
+	store := resultStore(opts.cacheDir)
+	plat, defaultJobs := newPlatform(rulesPath, cachedSkuCatalog(newSkuCatalog(opts.skuCatalog), store, opts.skuCatalog))
+	plat.ValidationLimit = opts.timeout
+	plat.ResultCache = store
+	jobIDs, err := selectJobs(plat, defaultJobs, opts.jobs, opts.excludeJobs)
+	if err != nil {
+		log.Fatalf(color.RedString("failed to select jobs: ") + err.Error())
+	}
+	plan, err := plat.Plan(ctx, jobIDs)
+	if err != nil {
+		log.Fatalf(color.RedString("failed to create pipeline: ") + err.Error())
+	}
+	log.Printf(boundedStrLayout, plan.Text())
+
+	if isBatch(target) {
+		if !validateBatch(ctx, plat, jobIDs, target, opts) {
+			os.Exit(1)
+		}
+		return
+	}
+
+	start := time.Now()
+
+	res, err := validateFile(ctx, plat, jobIDs, target, opts, !opts.noProgress)
+	if err != nil {
+		log.Fatalf("failed to validate %s: %s", target, color.RedString(err.Error()))
+	}
+
+	summary := res.report.BySeverity
+	log.Printf(boundedStrLayout, fmt.Sprintf("errors: %s\nwarnings: %s\ninfo: %s",
+		color.RedString("%d", summary[goexel.SeverityError]),
+		color.YellowString("%d", summary[goexel.SeverityWarning]),
+		color.BlueString("%d", summary[goexel.SeverityInfo]),
+	))
+
+	if opts.edgeStats {
+		log.Printf(boundedStrLayout, edgeStatsText(res.edges))
+	}
+	if opts.cacheDir != "" {
+		log.Printf(boundedStrLayout, cacheStatsText(res.cache))
+	}
+
+	timeElapsed := time.Since(start).Seconds()
+	timeStr := color.GreenString("%f", timeElapsed)
+	if timeElapsed > 120 {
+		timeStr = color.RedString("%f", timeElapsed)
+	} else if timeElapsed > 60 {
+		timeStr = color.YellowString("%f", timeElapsed)
+	} else if timeElapsed > 40 {
+		timeStr = color.BlueString("%f", timeElapsed)
+	}
+
+	decoded := "decoded whole"
+	if res.streamed {
+		decoded = "streamed"
+	}
+	log.Printf(boundedStrLayout, fmt.Sprintf("end of validation:\ntime is:  %s\nrows:     %d, %s", timeStr, res.rows, decoded))
+
+	// по коду возврата можно понять в скриптах, есть ли в файле ошибки
+	if res.hasErrors {
+		os.Exit(1)
+	}
+}
+
+// fileResult - итог валидации одного файла
+type fileResult struct {
+	report    goexel.Report
+	hasErrors bool
+	edges     []platform.EdgeStats
+	cache     []platform.CacheStats
+	rows      int
+	// streamed - строки шли в джобы пачками, таблицу целиком не декодировали
+	streamed bool
+}
+
+// edgeStatsText - подписки, на которых отправители ждали дольше всего
+func edgeStatsText(edges []platform.EdgeStats) string {
+	sb := &strings.Builder{}
+	sb.WriteString("waiting for subscribers:")
+	for _, edge := range edges {
+		if edge.Blocked == 0 {
+			continue
+		}
+		fmt.Fprintf(sb, "\n%s -> %s: %s in %d of %d sends, max %s, buffer %d",
+			edge.From, edge.To, color.YellowString(edge.BlockedTime.String()),
+			edge.Blocked, edge.Sends, edge.MaxBlocked, edge.Buffer)
+	}
+	return sb.String()
+}
+
+// resultStore - кеш результатов строк на диске, если задана папка, nil - без кеша
+func resultStore(dir string) platform.ResultStore {
+	if dir == "" {
+		return nil
+	}
+	store, err := cache.NewDisk(dir)
+	if err != nil {
+		log.Fatalf("failed to open result cache: %s", color.RedString(err.Error()))
+	}
+	return store
+}
+
+// cachedSkuCatalog - в тот же кеш кладем найденные в каталоге СКУ: SkuChecker зависит от другой джобы,
+// кеш строк его не берет, а так повторная проверка файла не ходит в каталог за теми же СКУ.
+// Встроенный список и выгрузка и так в памяти
+func cachedSkuCatalog(skuCatalog jobs.SkuCatalog, store platform.ResultStore, spec string) jobs.SkuCatalog {
+	if store == nil || skuCatalog == nil {
+		return skuCatalog
+	}
+	if _, inMemory := skuCatalog.(catalog.Set); inMemory {
+		return skuCatalog
+	}
+	return catalog.NewCached(skuCatalog, store, spec)
+}
+
+// cacheStatsText - сколько строк каждая джоба взяла из кеша
+func cacheStatsText(stats []platform.CacheStats) string {
+	sb := &strings.Builder{}
+	sb.WriteString("result cache:")
+	for _, s := range stats {
+		fmt.Fprintf(sb, "\n%s: %s hits, %s recomputed", s.JobID, color.GreenString("%d", s.Hits), color.YellowString("%d", s.Misses))
+	}
+	return sb.String()
+}
+
+// validateFile - прогоняет через jobIDs один файл и сохраняет размеченный файл и отчет рядом с ним,
+// если в opts не сказано иначе. Копии джоб у каждого пайплайна свои, так что файлы можно гонять параллельно
+func validateFile(
+	ctx context.Context,
+	plat *platform.Platform,
+	jobIDs []platform.JobID,
+	filepath string,
+	opts cliOptions,
+	progress bool,
+) (*fileResult, error) {
+	format := goexel.DetectFormat(filepath)
+	outFormat := format
+	if opts.format != "" {
+		outFormat = goexel.Format(strings.ToLower(opts.format))
+	}
+
+	//nolint:gosec
+	bytes, err := os.ReadFile(filepath)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open file")
+	}
+	// xlsx читаем потоком, только если пайплайну не нужна вся таблица. В наборе по умолчанию есть
+	// батчевая джоба и сверка СКУ с каталогом, так что потоком идут только свои наборы --jobs
+	wholeTable, err := plat.NeedsWholeTable(ctx, jobIDs)
+	if err != nil {
+		return nil, err
+	}
+	var ff *goexel.File[jobs.Entry]
+	if format == goexel.FormatXLSX && !wholeTable {
+		ff, err = goexel.NewStreamFile[jobs.Entry](bytes, goexel.WithSummarySheet(), goexel.WithSheet(opts.sheet))
+	} else {
+		ff, err = goexel.NewFileByFormat[jobs.Entry](bytes, format, goexel.WithSummarySheet(), goexel.WithSheet(opts.sheet))
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode file")
+	}
+
+	ctx = goexel.SetFileContext(ctx, ff)
+
+	pipeline, err := plat.NewPipeline(ctx, jobIDs, ff.Len())
+	if err != nil {
+		return nil, err
+	}
+	// в пакетном режиме и в watch платформа одна на все файлы, так что закончившийся пайплайн
+	// с копиями джоб и их броадкастерами из реестра убираем, статистику берем до этого
+	defer func() {
+		if err := plat.RemovePipeline(pipeline.GetID()); err != nil {
+			log.Printf("failed to remove pipeline: %s", color.RedString(err.Error()))
+		}
+	}()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var statDone chan struct{}
+	if progress {
+		statDone = make(chan struct{})
+		go func() {
+			defer close(statDone)
+			printStat(ctx, plat, pipeline.GetID())
+		}()
+	}
+
+	err = plat.StartPipeline(ctx, pipeline)
+	if progress {
+		cancel()
+		<-statDone
+		fmt.Printf("\n\n")
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to validate file")
+	}
+
+	base := strings.TrimSuffix(filepath, path.Ext(filepath))
+	if opts.outDir != "" {
+		base = path.Join(opts.outDir, path.Base(base))
+	}
+	reportFile := base + "_report.json"
+	if outFormat == formatJSON && opts.out != "" {
+		reportFile = opts.out
+	}
+	if outFormat != formatJSON {
+		fileWithComments := ff.CellRegister.GetFileBytesAs(outFormat)
+		if fileWithComments != nil {
+			destFile := opts.out
+			if destFile == "" {
+				destFile = fmt.Sprintf("%s_new_val_comm%s", base, outFormat.Ext())
+			}
+			//nolint:gosec
+			if err = os.WriteFile(destFile, fileWithComments, 0666); err != nil {
+				return nil, errors.Wrap(err, "failed to save file with comments")
+			}
+			log.Printf("file has been saved to %s ", color.BlackString(destFile))
+		}
+	}
+
+	report, err := ff.CellRegister.GetReportBytes()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build report")
+	}
+	//nolint:gosec
+	if err = os.WriteFile(reportFile, report, 0666); err != nil {
+		return nil, errors.Wrap(err, "failed to save report")
+	}
+	log.Printf("report has been saved to %s ", color.BlackString(reportFile))
+
+	edges, _ := plat.GetEdgeStats(pipeline.GetID())
+	cacheStats, _ := plat.GetCacheStats(pipeline.GetID())
+	return &fileResult{
+		report:    ff.CellRegister.GetReport(),
+		hasErrors: ff.CellRegister.HasErrors(),
+		edges:     edges,
+		cache:     cacheStats,
+		rows:      ff.Len(),
+		streamed:  ff.Streaming(),
+	}, nil
+}
+
+func printStat(ctx context.Context, p *platform.Platform, pipeID platform.PipelineID) {
+	count := 200
+	ticker := time.NewTicker(2 * time.Millisecond)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			min := math.MaxFloat32
+			prog, err := p.GetProgress(pipeID)
+			if err != nil {
+				logger.Errorf(ctx, "failed to get validation progress: %v", err)
+			}
+			for _, p := range prog {
+				if p < min && p != 0 {
+					min = p
+				}
+			}
+
+			l := int(float64(count) * min)
+			if l <= 0 {
+				l = 1
+			}
+			ost := count - l
+
+			fmt.Print("\033[G\033[K")
+			fmt.Printf("\n%s%s",
+				color.New(color.BgGreen, color.FgHiGreen).Sprint(strings.Repeat(" ", l)),
+				color.New(color.BgBlack).Sprint(strings.Repeat(" ", ost)),
+			)
+			fmt.Print("\033[A")
+		}
+	}
+}
+
+// defaultSkuCatalog - СКУ, которые считаются существующими, если каталог не задан
+var defaultSkuCatalog = catalog.Set{
+	326585538:  {},
+	327110952:  {},
+	1020030897: {},
+	783714036:  {},
+	608775475:  {},
+	425637863:  {},
+	505028007:  {},
+}
+
+const skuCatalogUsage = "sku catalog: csv/json dump, redis://host:port/set-key or http(s) lookup url, a built-in list by default"
+
+// newSkuCatalog - каталог СКУ по адресу из --sku-catalog, nil - встроенный список
+func newSkuCatalog(spec string) jobs.SkuCatalog {
+	if spec == "" {
+		return nil
+	}
+	u, err := url.Parse(spec)
+	if err == nil {
+		switch u.Scheme {
+		case "http", "https":
+			return catalog.NewHTTP(spec)
+		case "redis":
+			key := strings.TrimPrefix(u.Path, "/")
+			if key == "" {
+				log.Fatalf("sku catalog %s has no set key", color.RedString(spec))
+			}
+			return catalog.NewRedis(redis.NewClient(&redis.Options{Addr: u.Host}), key)
+		}
+	}
+	set, err := catalog.LoadFile(spec)
+	if err != nil {
+		log.Fatalf("failed to load sku catalog: %s", color.RedString(err.Error()))
+	}
+	return set
+}
+
+// newPlatform - платформа со всеми джобами валидатора и правилами из rulesPath,
+// вторым значением джобы, которые запускаются по умолчанию. skuCatalog nil - встроенный список СКУ
+func newPlatform(rulesPath string, skuCatalog jobs.SkuCatalog) (*platform.Platform, []platform.JobID) {
 	plat := platform.NewPlatform(time.Minute, platform.JobPool{
 		JobMap: make(map[platform.JobID]platform.Job),
 	})
-	// доделать
+	if skuCatalog == nil {
+		skuCatalog = defaultSkuCatalog
+	}
 	skuChecker := &jobs.SkuChecker{
 		JobWrapper: &platform.JobWrapper{ResChan: &broadcaster.Broadcaster[platform.JobResult]{}},
-		Exists: map[int64]struct{}{
-			326585538:  {},
-			327110952:  {},
-			1020030897: {},
-			783714036:  {},
-			608775475:  {},
-			425637863:  {},
-			505028007:  {},
-		}}
+		Catalog:    skuCatalog,
+	}
 	plat.AddJob(skuChecker)
 
 	skuValidator := &jobs.IsSkuValid{
@@ -83,7 +501,10 @@ func main() {
 	plat.AddJob(batchVolumeValidation)
 
 	clusterValidation := &jobs.IsClusterValid{
-		JobWrapper: &platform.JobWrapper{ResChan: &broadcaster.Broadcaster[platform.JobResult]{}},
+		// батчевая джоба читает кластеры пачками по СКУ, пусть не держит остальных подписчиков
+		JobWrapper: &platform.JobWrapper{ResChan: broadcaster.NewBroadcaster[platform.JobResult](
+			broadcaster.WithSubscriberBuffer(string(batchVolumeValidation.GetID()), batchResultBuffer),
+		)},
 		ValidClusters: map[string]struct{}{
 			"ФФ БО":            {},
 			"Федеральный":      {},
@@ -100,94 +521,98 @@ func main() {
 	}
 	plat.AddJob(clusterValidation)
 
-	start := time.Now()
-
-	bytes, _ := io.ReadAll(validationFile)
-	ff, err := goexel.NewFile[jobs.Entry](bytes)
-	if err != nil {
-		logger.Fatal(ctx, "failed to decode file: %v", err)
+	var ruleIDs []platform.JobID
+	if rulesPath != "" {
+		cfg, err := rules.LoadFile(rulesPath)
+		if err != nil {
+			log.Fatalf("failed to load rules: %s", color.RedString(err.Error()))
+		}
+		ruleJobs, err := rules.Jobs[jobs.Entry](cfg, &platform.JobWrapper{ResChan: &broadcaster.Broadcaster[platform.JobResult]{}})
+		if err != nil {
+			log.Fatalf("failed to compile rules: %s", color.RedString(err.Error()))
+		}
+		for _, job := range ruleJobs {
+			if err = plat.AddJob(job); err != nil {
+				log.Fatalf("failed to register rule: %s", color.RedString(err.Error()))
+			}
+			ruleIDs = append(ruleIDs, job.GetID())
+		}
 	}
-	ff.CellRegister.SetSheet(ff.Table[0].PromoName.GetSheetName())
 
-	ctx = goexel.SetFileContext(ctx, ff)
+	return plat, append([]platform.JobID{
+		funValidator.GetID(),
+		skuChecker.GetID(),
+		batchVolumeValidation.GetID(),
+	}, ruleIDs...)
+}
 
-	pipeline, err := plat.NewPipeline(ctx,
-		[]platform.JobID{
-			funValidator.GetID(),
-			skuChecker.GetID(),
-			batchVolumeValidation.GetID(),
-		},
-		len(ff.Table),
-	)
-	if err != nil {
-		log.Fatalf(color.RedString("failed to create pipeline: ") + err.Error())
+// selectJobs - only через запятую заменяет джобы по умолчанию, exclude убирает джобы из набора.
+// Зависимости оставшихся джоб пайплайн все равно подтянет сам, поэтому исключить джобу,
+// от которой зависит оставшаяся, нельзя: молча она бы все равно запустилась
+func selectJobs(plat *platform.Platform, defaultJobs []platform.JobID, only, exclude string) ([]platform.JobID, error) {
+	known := make(map[platform.JobID][]platform.JobID)
+	for _, job := range plat.Jobs() {
+		known[job.GetID()] = job.GetDepIDs()
 	}
-	ctx, cancel := context.WithCancel(ctx)
-	defer cancel()
 
-	go printStat(ctx, plat, pipeline.GetID())
-
-	err = plat.StartPipeline(ctx, pipeline)
-	if err != nil {
-		log.Fatalf(color.RedString("failed to validate file: ") + err.Error())
+	res := defaultJobs
+	if ids := splitJobIDs(only); len(ids) != 0 {
+		res = ids
 	}
-	cancel()
-	fmt.Printf("\n\n")
-
-	fileWithComments := ff.CellRegister.GetFileBytes()
-	if fileWithComments != nil {
-		destFile := fmt.Sprintf("%s_new_val_comm.xlsx", strings.TrimSuffix(filepath, ".xlsx"))
-		//nolint:gosec
-		if err = os.WriteFile(destFile, fileWithComments, 0666); err != nil {
-			log.Fatalf("failed to save file with comments: %v", color.RedString(err.Error()))
+	excludeIDs := splitJobIDs(exclude)
+	for _, ids := range [][]platform.JobID{res, excludeIDs} {
+		for _, jobID := range ids {
+			if _, exists := known[jobID]; !exists {
+				return nil, errors.Errorf("no job with id %s, see list-jobs", jobID)
+			}
 		}
-		log.Printf("file has been saved to %s ", color.BlackString(destFile))
 	}
-
-	timeElapsed := time.Since(start).Seconds()
-	timeStr := color.GreenString("%f", timeElapsed)
-	if timeElapsed > 120 {
-		timeStr = color.RedString("%f", timeElapsed)
-	} else if timeElapsed > 60 {
-		timeStr = color.YellowString("%f", timeElapsed)
-	} else if timeElapsed > 40 {
-		timeStr = color.BlueString("%f", timeElapsed)
+	excluded := make(map[platform.JobID]struct{}, len(excludeIDs))
+	for _, jobID := range excludeIDs {
+		excluded[jobID] = struct{}{}
 	}
 
-	log.Printf(boundedStrLayout, fmt.Sprintf("end of validation:\ntime is:  %s", timeStr))
-}
+	selected := make([]platform.JobID, 0, len(res))
+	for _, jobID := range res {
+		if _, skip := excluded[jobID]; !skip {
+			selected = append(selected, jobID)
+		}
+	}
+	if len(selected) == 0 {
+		return nil, errors.New("no jobs left to run")
+	}
 
-func printStat(ctx context.Context, p *platform.Platform, pipeID platform.PipelineID) {
-	count := 200
-	ticker := time.NewTicker(2 * time.Millisecond)
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		case <-ticker.C:
-			min := math.MaxFloat32
-			prog, err := p.GetProgress(pipeID)
-			if err != nil {
-				logger.Errorf(ctx, "failed to get validation progress: %w", err)
-			}
-			for _, p := range prog {
-				if p < min && p != 0 {
-					min = p
-				}
+	// обходим зависимости так же, как FetchJobDeps
+	visited := make(map[platform.JobID]struct{})
+	var check func(jobID platform.JobID) error
+	check = func(jobID platform.JobID) error {
+		if _, seen := visited[jobID]; seen {
+			return nil
+		}
+		visited[jobID] = struct{}{}
+		for _, depID := range known[jobID] {
+			if _, skip := excluded[depID]; skip {
+				return errors.Errorf("%s is required by %s", depID, jobID)
 			}
-
-			l := int(float64(count) * min)
-			if l <= 0 {
-				l = 1
+			if err := check(depID); err != nil {
+				return err
 			}
-			ost := count - l
+		}
+		return nil
+	}
+	for _, jobID := range selected {
+		if err := check(jobID); err != nil {
+			return nil, err
+		}
+	}
+	return selected, nil
+}
 
-			fmt.Print("\033[G\033[K")
-			fmt.Printf("\n%s%s",
-				color.New(color.BgGreen, color.FgHiGreen).Sprint(strings.Repeat(" ", l)),
-				color.New(color.BgBlack).Sprint(strings.Repeat(" ", ost)),
-			)
-			fmt.Print("\033[A")
+func splitJobIDs(raw string) (res []platform.JobID) {
+	for _, jobID := range strings.Split(raw, ",") {
+		if jobID = strings.TrimSpace(jobID); jobID != "" {
+			res = append(res, platform.JobID(jobID))
 		}
 	}
+	return res
 }
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+	"gitlab.ozon.ru/validator/goexel"
+	"gitlab.ozon.ru/validator/goexel/goexeltest"
+	"gitlab.ozon.ru/validator/platform"
+)
+
+// writeEntryBook - книга с колонками SKU и Комментарий, под таблицей blankTail строк только с оформлением
+func writeEntryBook(t *testing.T, dir, name string, blankTail int, skus ...string) string {
+	t.Helper()
+	book := goexeltest.SkuBook(t, skus...)
+	if blankTail > 0 {
+		style, err := book.NewStyle(&excelize.Style{Fill: excelize.Fill{Type: "pattern", Color: []string{"#FFFF00"}, Pattern: 1}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		from, to := len(skus)+2, len(skus)+1+blankTail
+		if err = book.SetCellStyle("Sheet1", "A"+strconv.Itoa(from), "B"+strconv.Itoa(to), style); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	file := filepath.Join(dir, name)
+	if err := book.SaveAs(file); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+func TestValidateFileStreams(t *testing.T) {
+	dir := t.TempDir()
+	file := writeEntryBook(t, dir, "promo.xlsx", 5, "5", "1", "7")
+	plat, defaultJobs := newPlatform("", nil)
+
+	for _, tc := range []struct {
+		name     string
+		jobIDs   []platform.JobID
+		streamed bool
+	}{
+		// построчным джобам таблица целиком не нужна, файл идет потоком
+		{name: "line jobs", jobIDs: []platform.JobID{"Валидный ли Ску"}, streamed: true},
+		// а в наборе по умолчанию есть батчевая джоба и сверка СКУ по всей таблице
+		{name: "default jobs", jobIDs: defaultJobs},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			res, err := validateFile(context.Background(), plat, tc.jobIDs, file, cliOptions{outDir: t.TempDir()}, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.streamed != tc.streamed {
+				t.Fatalf("expected streamed=%v", tc.streamed)
+			}
+			// оформленный хвост без значений строками не считается
+			if res.rows != 3 {
+				t.Fatalf("expected 3 rows, got %d", res.rows)
+			}
+			var empty []goexel.Finding
+			for _, finding := range res.report.Findings {
+				if finding.JobID == "Валидный ли Ску" {
+					empty = append(empty, finding)
+				}
+			}
+			if len(empty) != 1 || empty[0].Row != 3 {
+				t.Fatalf("expected one empty sku finding on row 3, got %+v", empty)
+			}
+		})
+	}
+}
+
+func TestSelectJobs(t *testing.T) {
+	plat, defaultJobs := newPlatform("", nil)
+	for _, tc := range []struct {
+		name, only, exclude string
+		err                 string
+	}{
+		{name: "default"},
+		{name: "excluded dependency", exclude: "Валидация кластеров", err: "Валидация кластеров is required by Относительный объем"},
+		{name: "excluded with dependents", exclude: "Валидация кластеров, Относительный объем"},
+		{name: "dependency of only", only: "СКУ В МАПЕ ЧЕКЕР", exclude: "Валидный ли Ску", err: "Валидный ли Ску is required by СКУ В МАПЕ ЧЕКЕР"},
+		{name: "unknown", exclude: "Нет такой", err: "no job with id Нет такой, see list-jobs"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			jobIDs, err := selectJobs(plat, defaultJobs, tc.only, tc.exclude)
+			if tc.err != "" {
+				if err == nil || err.Error() != tc.err {
+					t.Fatalf("expected error %q, got %v", tc.err, err)
+				}
+				return
+			}
+			if err != nil || len(jobIDs) == 0 {
+				t.Fatalf("unexpected jobs %v: %v", jobIDs, err)
+			}
+		})
+	}
+}
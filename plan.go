@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path"
+
+	"github.com/fatih/color"
+)
+
+// plan - что запустится для выбранных джоб, без самой валидации: plan [flags] [/path/to/rules.yaml]
+func plan(args []string) {
+	var only, exclude, format string
+	fs := flag.NewFlagSet(path.Base(os.Args[0])+" plan", flag.ExitOnError)
+	fs.StringVar(&only, "jobs", "", "comma separated jobs to plan instead of the default ones")
+	fs.StringVar(&exclude, "exclude-jobs", "", "comma separated jobs to skip")
+	fs.StringVar(&format, "format", "text", "output format: text|dot|mermaid")
+	_ = fs.Parse(args)
+
+	var rulesPath string
+	if fs.NArg() > 0 {
+		rulesPath = fs.Arg(0)
+	}
+	plat, defaultJobs := newPlatform(rulesPath, nil)
+	jobIDs, err := selectJobs(plat, defaultJobs, only, exclude)
+	if err != nil {
+		log.Fatalf(color.RedString("failed to select jobs: ") + err.Error())
+	}
+
+	// при цикле план тоже есть, в нем подсвечен путь цикла
+	res, planErr := plat.Plan(context.Background(), jobIDs)
+	if res != nil {
+		switch format {
+		case "text":
+			fmt.Print(res.Text())
+		case "dot":
+			fmt.Print(res.DOT())
+		case "mermaid":
+			fmt.Print(res.Mermaid())
+		default:
+			log.Fatalf("unknown plan format %s", color.RedString(format))
+		}
+	}
+	if planErr != nil {
+		log.Fatalf(color.RedString("failed to plan pipeline: ") + planErr.Error())
+	}
+}
@@ -0,0 +1,281 @@
+package platform
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"reflect"
+	"sort"
+	"strings"
+	"sync/atomic"
+
+	goxlsx "gitlab.ozon.ru/express/platform/lib/go-xlsx"
+	"gitlab.ozon.ru/platform/errors"
+	"gitlab.ozon.ru/platform/tracer-go/logger"
+	"gitlab.ozon.ru/validator/goexel"
+)
+
+// ResultStore - хранилище закешированных результатов строк, реализации в пакете cache
+type ResultStore interface {
+	// Get - found == false, если ключа нет
+	Get(ctx context.Context, key string) (val []byte, found bool, err error)
+	Set(ctx context.Context, key string, val []byte) error
+}
+
+// Cacheable - опциональный интерфейс для Runner: результат строки зависит только от ее полей и настроек джобы,
+// поэтому при повторной валидации исправленного файла его можно взять из Platform.ResultCache.
+// Кешируются только построчные RowIndependent джобы без зависимостей
+type Cacheable interface {
+	// CacheVersion - версия логики и настроек джобы, сменилась - старые результаты не подходят, см ConfigVersion
+	CacheVersion() string
+	// CacheFields - поля строки (имя поля или заголовок колонки), которые читает джоба, пусто - вся строка
+	CacheFields() []string
+}
+
+func isCacheable(job Job) bool {
+	_, ok := job.(Cacheable)
+	return ok && isShardable(job) && granularity(job) == ByLine
+}
+
+// ConfigVersion - версия для CacheVersion: base плюс хеш настроек джобы, например ее справочников
+func ConfigVersion(base string, config ...interface{}) string {
+	h := sha256.New()
+	for _, c := range config {
+		// ключи мап json сортирует, так что хеш от запуска к запуску один
+		data, _ := json.Marshal(c)
+		h.Write(data)
+	}
+	return base + ":" + hex.EncodeToString(h.Sum(nil))[:12]
+}
+
+// CacheStats - сколько строк джоба взяла из кеша, а сколько посчитала заново
+type CacheStats struct {
+	JobID  JobID
+	Hits   int64
+	Misses int64
+}
+
+// GetCacheStats - статистика кеша по джобам пайплайна, пусто если Platform.ResultCache не задан
+func (p *Platform) GetCacheStats(pipeID PipelineID) ([]CacheStats, error) {
+	p.mu.RLock()
+	pipe, exists := p.pipelines[pipeID]
+	p.mu.RUnlock()
+	if !exists {
+		return nil, errors.Wrap(ErrPipelineNotFound, string(pipeID))
+	}
+
+	res := make([]CacheStats, 0, len(pipe.caches))
+	for jobID, cache := range pipe.caches {
+		res = append(res, CacheStats{
+			JobID:  jobID,
+			Hits:   atomic.LoadInt64(&cache.hits),
+			Misses: atomic.LoadInt64(&cache.misses),
+		})
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].JobID < res[j].JobID
+	})
+	return res, nil
+}
+
+const rowCacheKey ctxJobKey = 5
+
+// rowCache - кеш строк одной джобы пайплайна
+type rowCache struct {
+	store   ResultStore
+	jobID   JobID
+	version string
+	fields  []string
+	resType reflect.Type
+
+	hits   int64
+	misses int64
+	// failed - об ошибках хранилища пишем в лог один раз, дальше просто считаем промахи
+	failed int32
+}
+
+// cacheEntry - что лежит в хранилище: результат строки и замечания, которые джоба на ней оставила
+type cacheEntry struct {
+	Result json.RawMessage `json:"result"`
+	Notes  []goexel.Note   `json:"notes,omitempty"`
+}
+
+// newRowCaches - кеши для всех джоб, которые можно кешировать, nil без хранилища
+func newRowCaches(store ResultStore, jobs []Job) map[JobID]*rowCache {
+	if store == nil {
+		return nil
+	}
+	res := make(map[JobID]*rowCache)
+	for _, job := range jobs {
+		if !isCacheable(job) {
+			continue
+		}
+		cacheable := job.(Cacheable)
+		cache := &rowCache{
+			store:   store,
+			jobID:   job.GetID(),
+			version: cacheable.CacheVersion(),
+			fields:  cacheable.CacheFields(),
+		}
+		if typer, ok := job.(ResultTyper); ok {
+			cache.resType = typer.GetResultType()
+		}
+		res[job.GetID()] = cache
+	}
+	return res
+}
+
+func withRowCache(ctx context.Context, cache *rowCache) context.Context {
+	return context.WithValue(ctx, rowCacheKey, cache)
+}
+
+// cachedLines - lineRunner, который сначала ищет результат строки в кеше джобы, а на промахе считает его
+// и запоминает вместе с замечаниями. Замечания из кеша повторяются на текущей позиции строки,
+// поэтому строки можно переставлять. Если кеша в контексте нет, отдает lineRunner как есть
+func cachedLines[T any](
+	ctx context.Context,
+	lineRunner func(c context.Context, register *goexel.FileCellRegisterer, row *T) JobResult,
+) func(c context.Context, register *goexel.FileCellRegisterer, row *T) JobResult {
+	cache, ok := ctx.Value(rowCacheKey).(*rowCache)
+	if !ok {
+		return lineRunner
+	}
+	fields, err := cacheFields(TypeOf[T](), cache.fields)
+	if err != nil {
+		logger.Errorf(ctx, "[%s]: result cache is off: %v", cache.jobID, err)
+		return lineRunner
+	}
+
+	return func(c context.Context, register *goexel.FileCellRegisterer, row *T) JobResult {
+		rowVal := reflect.ValueOf(row).Elem()
+		key := cache.key(rowVal, fields)
+		sheet, rowNumber := rowPosition(rowVal)
+		if res, notes, hit := cache.get(c, key); hit {
+			register.Replay(notes, sheet, rowNumber)
+			return res
+		}
+
+		recording, notes := register.Recording(sheet, rowNumber)
+		res := lineRunner(c, recording, row)
+		// ошибки, кроме пропуска строки, могут быть временными, а после дедлайна результат и вовсе не настоящий
+		if JobContext(c).Err() == nil && (res.Err == nil || errors.Is(res.Err, ErrSkipped)) {
+			cache.set(c, key, res, notes.List())
+		}
+		return res
+	}
+}
+
+// key - sha256 от джобы, ее версии и значений полей fields строки
+func (c *rowCache) key(row reflect.Value, fields []int) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00%s\x00", c.jobID, c.version)
+	for _, i := range fields {
+		fmt.Fprintf(h, "%s=", row.Type().Field(i).Name)
+		writeField(h, row.Field(i))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// writeField - у ячеек в хеш идет значение, пустота и валидность, но не позиция, иначе переехавшая строка не найдется
+func writeField(h hash.Hash, field reflect.Value) {
+	if cell, ok := field.Addr().Interface().(goxlsx.Type); ok {
+		fmt.Fprintf(h, "%t|%t|", cell.IsEmpty(), cell.IsValid())
+		if field.Kind() == reflect.Struct {
+			if value := field.FieldByName("Value"); value.IsValid() {
+				fmt.Fprintf(h, "%v", value.Interface())
+			}
+		}
+		return
+	}
+	fmt.Fprintf(h, "%v", field.Interface())
+}
+
+func (c *rowCache) get(ctx context.Context, key string) (res JobResult, notes []goexel.Note, hit bool) {
+	data, found, err := c.store.Get(ctx, key)
+	if err == nil && found {
+		var entry cacheEntry
+		if err = json.Unmarshal(data, &entry); err == nil {
+			if res, err = DecodeResult(entry.Result, c.resType); err == nil {
+				atomic.AddInt64(&c.hits, 1)
+				return res, entry.Notes, true
+			}
+		}
+	}
+	if err != nil {
+		c.fail(ctx, err)
+	}
+	atomic.AddInt64(&c.misses, 1)
+	return JobResult{}, nil, false
+}
+
+func (c *rowCache) set(ctx context.Context, key string, res JobResult, notes []goexel.Note) {
+	result, err := EncodeResult(res)
+	if err != nil {
+		c.fail(ctx, err)
+		return
+	}
+	data, err := json.Marshal(cacheEntry{Result: result, Notes: notes})
+	if err == nil {
+		err = c.store.Set(ctx, key, data)
+	}
+	if err != nil {
+		c.fail(ctx, err)
+	}
+}
+
+// fail - кеш не должен ломать валидацию, поэтому ошибки только логируем
+func (c *rowCache) fail(ctx context.Context, err error) {
+	if atomic.CompareAndSwapInt32(&c.failed, 0, 1) {
+		logger.Errorf(ctx, "[%s]: result cache: %v", c.jobID, err)
+	}
+}
+
+// cacheFields - индексы полей строки для ключа, по имени поля или тегу xlsx, пусто - все экспортируемые поля
+func cacheFields(rowType reflect.Type, names []string) ([]int, error) {
+	if rowType.Kind() != reflect.Struct {
+		return nil, errors.Errorf("row type %s is not a struct", rowType)
+	}
+	if len(names) == 0 {
+		res := make([]int, 0, rowType.NumField())
+		for i := 0; i < rowType.NumField(); i++ {
+			if rowType.Field(i).IsExported() {
+				res = append(res, i)
+			}
+		}
+		return res, nil
+	}
+
+	res := make([]int, 0, len(names))
+	for _, name := range names {
+		index := -1
+		for i := 0; i < rowType.NumField(); i++ {
+			f := rowType.Field(i)
+			if f.IsExported() && (f.Name == name || strings.TrimSpace(f.Tag.Get("xlsx")) == name) {
+				index = i
+				break
+			}
+		}
+		if index < 0 {
+			return nil, errors.Errorf("row type %s has no field %q", rowType, name)
+		}
+		res = append(res, index)
+	}
+	return res, nil
+}
+
+// rowPosition - лист и номер строки по первой ячейке строки, у которой они есть
+func rowPosition(row reflect.Value) (sheet string, rowNumber int) {
+	for i := 0; i < row.NumField(); i++ {
+		if !row.Type().Field(i).IsExported() {
+			continue
+		}
+		if cell, ok := row.Field(i).Addr().Interface().(goxlsx.Type); ok && cell.GetRowNumber() > 0 {
+			return cell.GetSheetName(), cell.GetRowNumber()
+		}
+	}
+	return "", 0
+}
@@ -0,0 +1,110 @@
+package platform
+
+import (
+	"encoding/json"
+	"reflect"
+
+	"gitlab.ozon.ru/platform/errors"
+)
+
+// knownErrors - ошибки платформы, которые после декодирования должны узнаваться через errors.Is
+var knownErrors = []struct {
+	kind string
+	err  error
+}{
+	{"skipped", ErrSkipped},
+	{"fatal", ErrFatal},
+	{"timeout", ErrJobTimeout},
+}
+
+func knownError(kind string) error {
+	for _, known := range knownErrors {
+		if known.kind == kind {
+			return known.err
+		}
+	}
+	return nil
+}
+
+type wireResult struct {
+	Res     json.RawMessage `json:"res,omitempty"`
+	Err     string          `json:"err,omitempty"`
+	ErrKind string          `json:"err_kind,omitempty"`
+	Row     int             `json:"row"`
+	Span    int             `json:"span"`
+}
+
+// EncodeResult - JobResult в json, чтобы отдать его в другой процесс или положить в кеш.
+// От ошибки остается текст, а ErrSkipped, ErrFatal и ErrJobTimeout еще и узнаются после DecodeResult
+func EncodeResult(res JobResult) ([]byte, error) {
+	wire := wireResult{Row: res.Row, Span: res.Span}
+	if res.Res != nil {
+		data, err := json.Marshal(res.Res)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to marshal %T", res.Res)
+		}
+		wire.Res = data
+	}
+	if res.Err != nil {
+		wire.Err = res.Err.Error()
+		for _, known := range knownErrors {
+			if errors.Is(res.Err, known.err) {
+				wire.ErrKind = known.kind
+				break
+			}
+		}
+	}
+	return json.Marshal(wire)
+}
+
+// DecodeResult - обратно из EncodeResult, Res поднимается в resType - тот же тип,
+// что джоба объявляет через ResultTyper, nil - как получится у json (числа станут float64)
+func DecodeResult(data []byte, resType reflect.Type) (res JobResult, err error) {
+	var wire wireResult
+	if err = json.Unmarshal(data, &wire); err != nil {
+		return res, err
+	}
+	res.Row, res.Span = wire.Row, wire.Span
+	if len(wire.Res) != 0 {
+		if res.Res, err = decodeRes(wire.Res, resType); err != nil {
+			return res, err
+		}
+	}
+	if wire.Err != "" {
+		res.Err = remoteErr(wire.Err, knownError(wire.ErrKind))
+	}
+	return res, nil
+}
+
+func decodeRes(data json.RawMessage, resType reflect.Type) (interface{}, error) {
+	if resType == nil {
+		var res interface{}
+		err := json.Unmarshal(data, &res)
+		return res, err
+	}
+	res := reflect.New(resType)
+	if err := json.Unmarshal(data, res.Interface()); err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal %s", resType)
+	}
+	return res.Elem().Interface(), nil
+}
+
+// remoteError - декодированная ошибка: текст как был, а под ним известная ошибка платформы
+type remoteError struct {
+	msg  string
+	kind error
+}
+
+func remoteErr(msg string, kind error) error {
+	if kind == nil {
+		return errors.New(msg)
+	}
+	if kind.Error() == msg {
+		return kind
+	}
+	return &remoteError{msg: msg, kind: kind}
+}
+
+func (e *remoteError) Error() string { return e.msg }
+func (e *remoteError) Unwrap() error { return e.kind }
+func (e *remoteError) Cause() error  { return e.kind }
@@ -0,0 +1,61 @@
+package platform
+
+import (
+	"context"
+	"time"
+
+	"gitlab.ozon.ru/platform/errors"
+)
+
+var (
+	// ErrJobTimeout - джоба не уложилась в свой дедлайн
+	// не фатальная: оставшиеся строки уходят подписчикам как ErrSkipped и пайплайн едет дальше
+	ErrJobTimeout = errors.New("job deadline exceeded")
+)
+
+// Deadliner - опциональный интерфейс для Runner, если джобе нужен собственный дедлайн
+// например для медленных походов во внешние сервисы
+type Deadliner interface {
+	GetDeadline() time.Duration
+}
+
+type ctxJobKey int8
+
+const (
+	jobDeadlineKey ctxJobKey = 1
+)
+
+// withJobDeadline - кладет в контекст пайплайна отдельный контекст джобы с таймаутом
+// сам контекст пайплайна не трогаем, чтобы отправка результатов подписчикам не отваливалась вместе с джобой
+func withJobDeadline(ctx context.Context, deadline time.Duration) (context.Context, context.CancelFunc) {
+	if deadline <= 0 {
+		return ctx, func() {}
+	}
+	jobCtx, cancel := context.WithTimeout(ctx, deadline)
+	return context.WithValue(ctx, jobDeadlineKey, jobCtx), cancel
+}
+
+// JobContext - контекст с учетом дедлайна джобы, его стоит отдавать во внешние вызовы внутри джобы
+// если дедлайна нет, то возвращается исходный контекст
+func JobContext(ctx context.Context) context.Context {
+	if jobCtx, ok := ctx.Value(jobDeadlineKey).(context.Context); ok {
+		return jobCtx
+	}
+	return ctx
+}
+
+// jobTimedOut - истек именно дедлайн джобы, а пайплайн еще жив
+func jobTimedOut(ctx context.Context) bool {
+	return ctx.Err() == nil && errors.Is(JobContext(ctx).Err(), context.DeadlineExceeded)
+}
+
+// deadline - дедлайн из пула важнее того, что объявила сама джоба
+func (p JobPool) deadline(job Job) time.Duration {
+	if d, exists := p.Deadlines[job.GetID()]; exists {
+		return d
+	}
+	if dl, ok := job.(Deadliner); ok {
+		return dl.GetDeadline()
+	}
+	return 0
+}
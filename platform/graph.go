@@ -1,5 +1,7 @@
 package platform
 
+import "sort"
+
 type graph map[JobID]*graphPoint
 
 type graphPoint struct {
@@ -30,10 +32,22 @@ func graphFromJobs(jobs map[JobID]Job) graph {
 	return res
 }
 
+// sortedIDs - обходим вершины в одном и том же порядке, чтобы план не зависел от порядка в мапе
+func (g graph) sortedIDs() []JobID {
+	ids := make([]JobID, 0, len(g))
+	for id := range g {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return ids[i] < ids[j]
+	})
+	return ids
+}
+
 func (g graph) HasCycles() []JobID {
-	for _, point := range g {
-		if point.color == white {
-			if cycle := g.hasCycles(point.Job.GetID()); len(cycle) != 0 {
+	for _, id := range g.sortedIDs() {
+		if g[id].color == white {
+			if cycle := g.hasCycles(id); len(cycle) != 0 {
 				return cycle
 			}
 		}
@@ -61,11 +75,13 @@ func (g graph) hasCycles(ind JobID) []JobID {
 	return nil
 }
 
+// TopSort - зависимости всегда раньше зависящих от них джоб, порядок детерминированный
+// перед вызовом граф должен быть белым
 func (g graph) TopSort() (res []Job) {
 	res = make([]Job, 0, len(g))
-	for _, point := range g {
-		if point.color != black {
-			res = g.topSort(point.Job.GetID(), res)
+	for _, id := range g.sortedIDs() {
+		if g[id].color != black {
+			res = g.topSort(id, res)
 		}
 	}
 	return res
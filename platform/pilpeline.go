@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"sync"
+	"time"
 
 	colorfmt "github.com/fatih/color"
 	"gitlab.ozon.ru/platform/errgroup/v2"
@@ -24,6 +26,16 @@ const (
 	Writer
 )
 
+func (t JobType) String() string {
+	switch t {
+	case Common:
+		return "common"
+	case Writer:
+		return "writer"
+	}
+	return "unknown"
+}
+
 // интерфейс который реально нужно имплементировать
 type Runner interface {
 	Run(ctx context.Context) (err error)
@@ -35,6 +47,11 @@ type Runner interface {
 type JobResult struct {
 	Res interface{}
 	Err error
+	// Row - индекс в File.Table первой строки, к которой относится результат
+	Row int
+	// Span - сколько строк покрывает результат: 1 у построчных джоб, размер батча у батчевых
+	// 0 - результат отправлен руками и ни к каким строкам не привязан
+	Span int
 }
 
 type Job interface {
@@ -58,18 +75,49 @@ var (
 type PipelineID string
 
 type Pipeline struct {
-	rJobs   []Job
-	wJobs   []Job
-	id      PipelineID
-	fileLen int
+	rJobs []Job
+	wJobs []Job
+	// requested - джобы, которые попросили явно, остальные подтянуты как зависимости
+	requested []JobID
+	id        PipelineID
+	fileLen   int
+	deadlines map[JobID]time.Duration
+	// sharded - джобы, строки которых гоняются параллельно пулом из workers воркеров
+	sharded map[JobID]bool
+	workers int
+	// caches - кеши строк Cacheable джоб, если у платформы есть ResultCache
+	caches map[JobID]*rowCache
+	// depChans - каналы, на которые подписана джоба
+	depChans map[JobID][]chan JobResult
+
+	// состояние для реестра платформы, меняется под мьютексом платформы
+	status     PipelineStatus
+	createdAt  time.Time
+	startedAt  time.Time
+	finishedAt time.Time
+	err        error
+	cancel     context.CancelFunc
+	cancelled  bool
+
+	// finishedJobs - джобы в порядке завершения, пишутся из горутин джоб, поэтому под своим мьютексом
+	jobsMu       sync.Mutex
+	finishedJobs []JobID
 }
 
-func (p Pipeline) GetID() PipelineID {
+func (p *Pipeline) GetID() PipelineID {
 	return p.id
 }
 
 // Start - стартует весь пайплайн из джоб
 func (p *Pipeline) start(ctx context.Context) (err error) {
+	// на любом выходе читателей у результатов больше нет, подписки вне процесса тоже останавливаем
+	defer p.stop()
+
+	// потоковый файл либо раздаем читателям по мере чтения, либо декодируем до врайтеров
+	stream, err := p.prepareStream(ctx)
+	if err != nil {
+		return err
+	}
 
 	// запускаем пишушщие джобы поочередно, чтобы не было гонок
 	// их резы никто не ждет
@@ -77,24 +125,49 @@ func (p *Pipeline) start(ctx context.Context) (err error) {
 		if ctx.Err() != nil {
 			return ctx.Err()
 		}
-		if err = wjob.Run(ctx); err != nil {
-			if errors.Is(err, ErrFatal) {
-				return err
-			}
-			logger.Errorf(ctx, "[%s]: %v", wjob.GetID(), err)
+		if err = p.runWriter(ctx, wjob); err != nil {
+			return err
 		}
+		p.jobFinished(wjob.GetID())
 	}
 
 	// параллельно запускаем все остальные джобы
 	// не можем экономить на горутинах из-за каналов
 	// нужно чтобы их кто-то читал...
 	group, ctx := errgroup.WithContext(ctx)
+	if stream != nil {
+		group.Go(func() error {
+			if err := stream.Stream(ctx); err != nil {
+				return errors.Wrap(ErrFatal, err.Error())
+			}
+			return nil
+		})
+	}
 	for _, job := range p.rJobs {
 		job := job
 		group.Go(func() error {
 			defer job.Close()
-			if err := job.Run(ctx); err != nil {
-				if errors.Is(err, ErrFatal) {
+			jobCtx, cancel := withJobDeadline(withJobID(ctx, job.GetID()), p.deadlines[job.GetID()])
+			defer cancel()
+			if p.sharded[job.GetID()] {
+				jobCtx = withJobWorkers(jobCtx, p.workers)
+			}
+			if cache := p.caches[job.GetID()]; cache != nil {
+				jobCtx = withRowCache(jobCtx, cache)
+			}
+			err := job.Run(jobCtx)
+			if stream != nil {
+				stream.Release(string(job.GetID()))
+			}
+			defer p.jobFinished(job.GetID())
+			// джоба могла прочитать не все результаты зависимостей (например пропустив строки),
+			// дочитываем за нее, иначе зависимость навсегда встанет на отправке
+			for _, ch := range p.depChans[job.GetID()] {
+				drain(ch)
+			}
+			if err != nil {
+				// фатальная ошибка из-за собственного дедлайна джобы останавливает только ее, как и у врайтеров
+				if errors.Is(err, ErrFatal) && !jobTimedOut(jobCtx) {
 					return err
 				}
 				logger.Errorf(ctx, "[%s]: %v", job.GetID(), err)
@@ -107,8 +180,43 @@ func (p *Pipeline) start(ctx context.Context) (err error) {
 	return group.Wait()
 }
 
+// stop - останавливает броадкастеры читающих джоб, см Stopper.
+// Зовется и для пайплайнов, которые так и не запустились: подписки на зависимости делает уже createPipeline
+func (p *Pipeline) stop() {
+	for _, job := range p.rJobs {
+		stopJob(job)
+	}
+}
+
+func stopJob(job Job) {
+	if stopper, ok := job.(Stopper); ok {
+		stopper.Stop()
+	}
+}
+
+func (p *Pipeline) jobFinished(jobID JobID) {
+	p.jobsMu.Lock()
+	p.finishedJobs = append(p.finishedJobs, jobID)
+	p.jobsMu.Unlock()
+}
+
+// runWriter - запускает пишущую джобу с ее дедлайном, не фатальные ошибки только логируем
+func (p *Pipeline) runWriter(ctx context.Context, wjob Job) error {
+	jobCtx, cancel := withJobDeadline(withJobID(ctx, wjob.GetID()), p.deadlines[wjob.GetID()])
+	defer cancel()
+	if err := wjob.Run(jobCtx); err != nil {
+		if errors.Is(err, ErrFatal) && !jobTimedOut(jobCtx) {
+			return err
+		}
+		logger.Errorf(ctx, "[%s]: %v", wjob.GetID(), err)
+	}
+	return nil
+}
+
 type JobPool struct {
 	JobMap map[JobID]Job
+	// Deadlines - дедлайны джоб, перекрывают то что джоба объявила через Deadliner
+	Deadlines map[JobID]time.Duration
 }
 
 // возвращает копию исходной джобы
@@ -122,30 +230,18 @@ func (p JobPool) Get(jobID JobID) (Job, bool) {
 
 // CreatePipeline - из id джоб собирает цепочку готовых к запуску джоб
 func (p JobPool) createPipeline(ctx context.Context, jobIDs []JobID) (res *Pipeline, err error) {
-	// jobs сет необходимых для конфигурации пайплайна джоб
-	jobs := make(map[JobID]Job, len(jobIDs))
-
-	for _, jobID := range jobIDs {
-		// получаем копию исходной джобы
-		// чтобы можно было мутировать внутренние данные для разных файлов одновременно
-		job, exists := p.Get(jobID)
-		if !exists {
-			return nil, &ConfigurationError{
-				Kind:           JobConfigurationError,
-				AdditionalInfo: []JobID{jobID},
-			}
-		}
-		// если она не была добавлена ранее как зависимость для другой джобы
-		if _, exists := jobs[jobID]; !exists {
-			jobs[job.GetID()] = job
-		}
-
-		// собираем все джобы, которые необходимо выполнять перед этой
-		jobs, err = p.FetchJobDeps(ctx, job, jobs)
+	jobs, err := p.resolveJobs(ctx, jobIDs)
+	if err != nil {
+		return nil, err
+	}
+	// на ошибке часть подписок уже сделана, а пайплайна, который их остановит, не будет
+	defer func() {
 		if err != nil {
-			return nil, err
+			for _, job := range jobs {
+				stopJob(job)
+			}
 		}
-	}
+	}()
 
 	// из сета джоб делаем граф
 	jobGraph := graphFromJobs(jobs)
@@ -160,15 +256,44 @@ func (p JobPool) createPipeline(ctx context.Context, jobIDs []JobID) (res *Pipel
 		}
 	}
 
-	pipe := &Pipeline{}
+	// дальше идем в топологическом порядке, чтобы врайтеры запускались после своих зависимостей
+	// и план пайплайна от запуска к запуску был одинаковым
+	jobGraph.SetWhite()
+	ordered := jobGraph.TopSort()
+
+	pipe := &Pipeline{
+		requested: jobIDs,
+		deadlines: make(map[JobID]time.Duration, len(jobs)),
+		sharded:   make(map[JobID]bool, len(jobs)),
+		depChans:  make(map[JobID][]chan JobResult, len(jobs)),
+	}
 	// для каждой джобы смотрим ее завсимости и просим у них канал их которого можно будет читать их апдейты
 	// такой вот Event Driven Design
-	for _, job := range jobs {
+	for _, job := range ordered {
+		if d := p.deadline(job); d > 0 {
+			pipe.deadlines[job.GetID()] = d
+		}
 		// врайтеры не пишут никому ничего, просто запускаются поочереди)
 		if job.GetType() == Writer {
+			// врайтеры идут раньше всех обычных джоб, поэтому зависеть могут только от врайтеров
+			for _, depID := range job.GetDepIDs() {
+				if jobs[depID].GetType() != Writer {
+					return nil, &ConfigurationError{
+						Kind:           WriterDependencyError,
+						AdditionalInfo: []JobID{job.GetID(), depID},
+					}
+				}
+			}
 			pipe.wJobs = append(pipe.wJobs, job)
 			continue
 		}
+		if isShardable(job) {
+			pipe.sharded[job.GetID()] = true
+		}
+
+		if err = checkDepTypes(job, jobs); err != nil {
+			return nil, err
+		}
 
 		depIDs := job.GetDepIDs()
 		for _, depID := range depIDs {
@@ -177,15 +302,58 @@ func (p JobPool) createPipeline(ctx context.Context, jobIDs []JobID) (res *Pipel
 			if dep.GetType() == Writer {
 				continue
 			}
+			if err = checkGranularity(job, dep); err != nil {
+				return nil, err
+			}
 			// подписываюсь на обновления этой джобы, а она мне канал
-			depChan := dep.Subscribe()
-			job.SetDependencyChan(depID, Chan{depChan})
+			depChan := subscribe(dep, job.GetID())
+			job.SetDependencyChan(depID, newChan(depChan))
+			pipe.depChans[job.GetID()] = append(pipe.depChans[job.GetID()], depChan)
 		}
 		pipe.rJobs = append(pipe.rJobs, job)
 	}
 	return pipe, nil
 }
 
+// resolveJobs - копии запрошенных джоб вместе со всеми их зависимостями, на циклы не проверяет
+func (p JobPool) resolveJobs(ctx context.Context, jobIDs []JobID) (jobs map[JobID]Job, err error) {
+	// jobs сет необходимых для конфигурации пайплайна джоб
+	jobs = make(map[JobID]Job, len(jobIDs))
+
+	for _, jobID := range jobIDs {
+		// получаем копию исходной джобы
+		// чтобы можно было мутировать внутренние данные для разных файлов одновременно
+		job, exists := p.Get(jobID)
+		if !exists {
+			return nil, &ConfigurationError{
+				Kind:           JobConfigurationError,
+				AdditionalInfo: []JobID{jobID},
+			}
+		}
+		// если она не была добавлена ранее как зависимость для другой джобы
+		if _, exists := jobs[jobID]; !exists {
+			jobs[job.GetID()] = job
+		}
+
+		// собираем все джобы, которые необходимо выполнять перед этой
+		jobs, err = p.FetchJobDeps(ctx, job, jobs)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return jobs, nil
+}
+
+// subscribe - подписывает job на dep, представляясь, если dep умеет подписывать по имени
+func subscribe(dep Job, jobID JobID) chan JobResult {
+	if named, ok := dep.(interface {
+		SubscribeAs(subscriber JobID) chan JobResult
+	}); ok {
+		return named.SubscribeAs(jobID)
+	}
+	return dep.Subscribe()
+}
+
 // FetchJobDeps - добавляет в глобальную мапу все недостающие, но необходимые подготовки джобы
 // так же достает все зависимотси для зависимостей нашей джобы (всю цепоку достаем)
 func (p JobPool) FetchJobDeps(ctx context.Context, job Job, jobMap map[JobID]Job) (res map[JobID]Job, err error) {
@@ -224,6 +392,8 @@ type ConfigurationError struct {
 	Kind ConfigurationErrorKind
 	// тут инфа которая зависит от типа ошибки, но тут точно имена джоб
 	AdditionalInfo []JobID
+	// Details - подробности в свободной форме, если одних имен джоб мало
+	Details string
 }
 
 type ConfigurationErrorKind int8
@@ -235,6 +405,14 @@ const (
 	CircleDependencyError
 	// CycleDependencyError - нашелся цикл из зависимостей
 	CycleDependencyError
+	// WriterDependencyError - пишущая джоба зависит от обычной, а обычные запускаются только после всех пишущих
+	WriterDependencyError
+	// ResultTypeError - джоба ждет от зависимости не тот тип результата, который та отдает
+	ResultTypeError
+	// GranularityError - построчная джоба читает через Recv батчевую зависимость, результаты разъедутся со строками
+	GranularityError
+	// UnknownDepTypeError - джоба объявила тип в GetDepTypes для джобы, которой нет в ее GetDepIDs
+	UnknownDepTypeError
 )
 
 // это по фану сделал, прикольно выглядит
@@ -254,6 +432,31 @@ func (e *ConfigurationError) Error() string {
 				string(e.AdditionalInfo[0]),
 			),
 		)
+	case WriterDependencyError:
+		return fmt.Sprintf(
+			"Изменяющая валидация %s не может зависеть от обычной валидации %s",
+			colorfmt.MagentaString(string(e.AdditionalInfo[0])),
+			colorfmt.MagentaString(string(e.AdditionalInfo[1])),
+		)
+	case ResultTypeError:
+		return fmt.Sprintf(
+			"Валидация %s ждет от %s результат другого типа: %s",
+			colorfmt.MagentaString(string(e.AdditionalInfo[0])),
+			colorfmt.MagentaString(string(e.AdditionalInfo[1])),
+			e.Details,
+		)
+	case UnknownDepTypeError:
+		return fmt.Sprintf(
+			"Валидация %s объявляет тип результата для %s, но не зависит от нее",
+			colorfmt.MagentaString(string(e.AdditionalInfo[0])),
+			colorfmt.MagentaString(string(e.AdditionalInfo[1])),
+		)
+	case GranularityError:
+		return fmt.Sprintf(
+			"Построчная валидация %s читает батчевую валидацию %s без привязки к строкам, нужен RecvFor",
+			colorfmt.MagentaString(string(e.AdditionalInfo[0])),
+			colorfmt.MagentaString(string(e.AdditionalInfo[1])),
+		)
 	case CycleDependencyError:
 		var (
 			unique = make(map[JobID]struct{}, len(e.AdditionalInfo))
@@ -289,10 +492,23 @@ func (e *ConfigurationError) Error() string {
 	return "Неизвестная ошибка"
 }
 
+// ExecutionPlan - текстовый план запуска: врайтеры по порядку, затем обычные джобы с их подписками
+// одинаковый для одного и того же набора джоб, можно выводить перед запуском
+func (p *Pipeline) ExecutionPlan() string {
+	return p.Plan().Text()
+}
+
 type PipelineProgress map[JobID]float64
 
 func (p *Pipeline) getProgress() (res PipelineProgress) {
 	res = make(map[JobID]float64, len(p.wJobs)+len(p.rJobs))
+	// в пустом файле делить не на что, там все джобы сразу закончены
+	if p.fileLen == 0 {
+		for _, job := range append(append([]Job{}, p.wJobs...), p.rJobs...) {
+			res[job.GetID()] = 1
+		}
+		return res
+	}
 	for _, job := range p.wJobs {
 		res[job.GetID()] = float64(job.GetProgress()) / float64(p.fileLen)
 	}
@@ -0,0 +1,926 @@
+package platform_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	goxlsx "gitlab.ozon.ru/express/platform/lib/go-xlsx"
+	"gitlab.ozon.ru/validator/broadcaster"
+	"gitlab.ozon.ru/validator/cache"
+	"gitlab.ozon.ru/validator/goexel"
+	"gitlab.ozon.ru/validator/goexel/goexeltest"
+	"gitlab.ozon.ru/validator/platform"
+)
+
+type row struct {
+	id int64
+}
+
+func (r row) GetItemID() int64 {
+	return r.id
+}
+
+type slowJob struct {
+	*platform.JobWrapper
+	deadline time.Duration
+}
+
+func (j *slowJob) Run(ctx context.Context) error {
+	return platform.RunByLine(ctx, j.JobWrapper, func(c context.Context, _ *goexel.FileCellRegisterer, r *row) platform.JobResult {
+		select {
+		case <-c.Done():
+		case <-time.After(time.Second):
+		}
+		return platform.JobResult{Res: true}
+	})
+}
+
+func (j *slowJob) GetDepIDs() []platform.JobID { return nil }
+func (j *slowJob) GetID() platform.JobID       { return "slow" }
+func (j *slowJob) GetType() platform.JobType   { return platform.Common }
+func (j *slowJob) GetDeadline() time.Duration  { return j.deadline }
+func (j *slowJob) Create() platform.Job {
+	return &slowJob{JobWrapper: j.JobWrapper.Create(), deadline: j.deadline}
+}
+
+type readerJob struct {
+	*platform.JobWrapper
+	skipped *int
+}
+
+func (j *readerJob) Run(ctx context.Context) error {
+	slow := j.Dependencies["slow"]
+	return platform.RunByLine(ctx, j.JobWrapper, func(c context.Context, _ *goexel.FileCellRegisterer, r *row) platform.JobResult {
+		res := slow.Recv(ctx)
+		if res.Err != nil {
+			*j.skipped++
+		}
+		return res
+	})
+}
+
+func (j *readerJob) GetDepIDs() []platform.JobID { return []platform.JobID{"slow"} }
+func (j *readerJob) GetID() platform.JobID       { return "reader" }
+func (j *readerJob) GetType() platform.JobType   { return platform.Common }
+func (j *readerJob) Create() platform.Job {
+	return &readerJob{JobWrapper: j.JobWrapper.Create(), skipped: j.skipped}
+}
+
+func newWrapper() *platform.JobWrapper {
+	return &platform.JobWrapper{ResChan: &broadcaster.Broadcaster[platform.JobResult]{}}
+}
+
+func TestJobDeadline(t *testing.T) {
+	ctx := context.Background()
+	file := &goexel.File[row]{Table: []*row{{id: 1}, {id: 2}, {id: 3}}}
+	ctx = goexel.SetFileContext(ctx, file)
+
+	skipped := 0
+	plat := platform.NewPlatform(5*time.Second, platform.JobPool{JobMap: map[platform.JobID]platform.Job{}})
+	_ = plat.AddJob(&slowJob{JobWrapper: newWrapper(), deadline: 20 * time.Millisecond})
+	_ = plat.AddJob(&readerJob{JobWrapper: newWrapper(), skipped: &skipped})
+
+	pipe, err := plat.NewPipeline(ctx, []platform.JobID{"reader"}, len(file.Table))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = plat.StartPipeline(ctx, pipe); err != nil {
+		t.Fatalf("pipeline must survive job deadline, got %v", err)
+	}
+	if skipped != len(file.Table) {
+		t.Fatalf("expected %d skipped rows, got %d", len(file.Table), skipped)
+	}
+}
+
+// fatalOnDeadlineJob - ждет свой дедлайн и отдает фатальную ошибку, как Recv на отмененном контексте
+type fatalOnDeadlineJob struct {
+	*platform.JobWrapper
+}
+
+func (j *fatalOnDeadlineJob) Run(ctx context.Context) error {
+	jobCtx := platform.JobContext(ctx)
+	<-jobCtx.Done()
+	return fmt.Errorf("%w: %v", platform.ErrFatal, jobCtx.Err())
+}
+
+func (j *fatalOnDeadlineJob) GetDepIDs() []platform.JobID { return nil }
+func (j *fatalOnDeadlineJob) GetID() platform.JobID       { return "fatal on deadline" }
+func (j *fatalOnDeadlineJob) GetType() platform.JobType   { return platform.Common }
+func (j *fatalOnDeadlineJob) GetDeadline() time.Duration  { return 20 * time.Millisecond }
+func (j *fatalOnDeadlineJob) Create() platform.Job {
+	return &fatalOnDeadlineJob{JobWrapper: j.JobWrapper.Create()}
+}
+
+func TestReaderDeadlineIsNotFatal(t *testing.T) {
+	file := &goexel.File[row]{Table: []*row{{id: 1}}}
+	ctx := goexel.SetFileContext(context.Background(), file)
+
+	plat := platform.NewPlatform(5*time.Second, platform.JobPool{JobMap: map[platform.JobID]platform.Job{}})
+	_ = plat.AddJob(&fatalOnDeadlineJob{JobWrapper: newWrapper()})
+	_ = plat.AddJob(&slowJob{JobWrapper: newWrapper()})
+
+	pipe, err := plat.NewPipeline(ctx, []platform.JobID{"fatal on deadline", "slow"}, len(file.Table))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = plat.StartPipeline(ctx, pipe); err != nil {
+		t.Fatalf("job deadline must stop only the job, got %v", err)
+	}
+}
+
+func TestValidationLimit(t *testing.T) {
+	ctx := context.Background()
+	file := &goexel.File[row]{Table: []*row{{id: 1}}}
+	ctx = goexel.SetFileContext(ctx, file)
+
+	skipped := 0
+	plat := platform.NewPlatform(20*time.Millisecond, platform.JobPool{JobMap: map[platform.JobID]platform.Job{}})
+	_ = plat.AddJob(&slowJob{JobWrapper: newWrapper()})
+	_ = plat.AddJob(&readerJob{JobWrapper: newWrapper(), skipped: &skipped})
+
+	pipe, err := plat.NewPipeline(ctx, []platform.JobID{"reader"}, len(file.Table))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = plat.StartPipeline(ctx, pipe); err == nil {
+		t.Fatal("expected validation limit to abort the pipeline")
+	}
+}
+
+func TestPipelineRegistry(t *testing.T) {
+	ctx := context.Background()
+	file := &goexel.File[row]{Table: []*row{{id: 1}}}
+	ctx = goexel.SetFileContext(ctx, file)
+
+	skipped := 0
+	plat := platform.NewPlatform(time.Second, platform.JobPool{JobMap: map[platform.JobID]platform.Job{}})
+	_ = plat.AddJob(&slowJob{JobWrapper: newWrapper(), deadline: time.Millisecond})
+	_ = plat.AddJob(&readerJob{JobWrapper: newWrapper(), skipped: &skipped})
+
+	first, err := plat.NewPipeline(ctx, []platform.JobID{"reader"}, len(file.Table))
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := plat.NewPipeline(ctx, []platform.JobID{"reader"}, len(file.Table))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.GetID() == "" || first.GetID() == second.GetID() {
+		t.Fatalf("pipeline ids must be unique, got %q and %q", first.GetID(), second.GetID())
+	}
+
+	if err = plat.CancelPipeline(second.GetID()); err != nil {
+		t.Fatal(err)
+	}
+	if err = plat.StartPipeline(ctx, second); err == nil {
+		t.Fatal("cancelled pipeline must not start")
+	}
+	if err = plat.StartPipeline(ctx, first); err != nil {
+		t.Fatal(err)
+	}
+
+	infos := plat.ListPipelines()
+	if len(infos) != 2 {
+		t.Fatalf("expected 2 pipelines in registry, got %d", len(infos))
+	}
+	info, err := plat.GetPipeline(first.GetID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Status != platform.PipelineFinished || info.FinishedAt.Before(info.StartedAt) || len(info.Jobs) != 2 {
+		t.Fatalf("unexpected pipeline info: %+v", info)
+	}
+	if info, _ = plat.GetPipeline(second.GetID()); info.Status != platform.PipelineCancelled {
+		t.Fatalf("expected cancelled status, got %s", info.Status)
+	}
+}
+
+type writerJob struct {
+	*platform.JobWrapper
+	id    platform.JobID
+	deps  []platform.JobID
+	order *[]platform.JobID
+}
+
+func (j *writerJob) Run(ctx context.Context) error {
+	*j.order = append(*j.order, j.id)
+	return nil
+}
+
+func (j *writerJob) GetDepIDs() []platform.JobID { return j.deps }
+func (j *writerJob) GetID() platform.JobID       { return j.id }
+func (j *writerJob) GetType() platform.JobType   { return platform.Writer }
+func (j *writerJob) Create() platform.Job {
+	return &writerJob{JobWrapper: j.JobWrapper.Create(), id: j.id, deps: j.deps, order: j.order}
+}
+
+func TestWritersOrder(t *testing.T) {
+	ctx := goexel.SetFileContext(context.Background(), &goexel.File[row]{})
+
+	var order []platform.JobID
+	plat := platform.NewPlatform(time.Second, platform.JobPool{JobMap: map[platform.JobID]platform.Job{}})
+	// "a" зависит от "c", поэтому "c" должна отработать раньше несмотря на алфавит
+	_ = plat.AddJob(&writerJob{JobWrapper: newWrapper(), id: "a", deps: []platform.JobID{"c"}, order: &order})
+	_ = plat.AddJob(&writerJob{JobWrapper: newWrapper(), id: "b", order: &order})
+	_ = plat.AddJob(&writerJob{JobWrapper: newWrapper(), id: "c", order: &order})
+
+	for i := 0; i < 20; i++ {
+		order = order[:0]
+		pipe, err := plat.NewPipeline(ctx, []platform.JobID{"b", "a"}, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = plat.StartPipeline(ctx, pipe); err != nil {
+			t.Fatal(err)
+		}
+		if len(order) != 3 || order[0] != "c" || order[1] != "a" || order[2] != "b" {
+			t.Fatalf("unexpected writers order: %v", order)
+		}
+	}
+}
+
+type shardedJob struct {
+	*platform.JobWrapper
+}
+
+func (j *shardedJob) Run(ctx context.Context) error {
+	return platform.RunByLine(ctx, j.JobWrapper, func(c context.Context, _ *goexel.FileCellRegisterer, r *row) platform.JobResult {
+		return platform.JobResult{Res: r.id}
+	})
+}
+
+func (j *shardedJob) GetDepIDs() []platform.JobID { return nil }
+func (j *shardedJob) GetID() platform.JobID       { return "sharded" }
+func (j *shardedJob) GetType() platform.JobType   { return platform.Common }
+func (j *shardedJob) IsRowIndependent() bool      { return true }
+func (j *shardedJob) Create() platform.Job {
+	return &shardedJob{JobWrapper: j.JobWrapper.Create()}
+}
+
+type orderJob struct {
+	*platform.JobWrapper
+	misordered *int
+}
+
+func (j *orderJob) Run(ctx context.Context) error {
+	sharded := j.Dependencies["sharded"]
+	return platform.RunByLine(ctx, j.JobWrapper, func(c context.Context, _ *goexel.FileCellRegisterer, r *row) platform.JobResult {
+		if res := sharded.Recv(ctx); res.Err != nil || res.Res.(int64) != r.id {
+			*j.misordered++
+		}
+		return platform.JobResult{}
+	})
+}
+
+func (j *orderJob) GetDepIDs() []platform.JobID { return []platform.JobID{"sharded"} }
+func (j *orderJob) GetID() platform.JobID       { return "order" }
+func (j *orderJob) GetType() platform.JobType   { return platform.Common }
+func (j *orderJob) Create() platform.Job {
+	return &orderJob{JobWrapper: j.JobWrapper.Create(), misordered: j.misordered}
+}
+
+func TestShardedJobKeepsRowOrder(t *testing.T) {
+	file := &goexel.File[row]{}
+	for i := 0; i < 2000; i++ {
+		file.Table = append(file.Table, &row{id: int64(i)})
+	}
+	ctx := goexel.SetFileContext(context.Background(), file)
+
+	misordered := 0
+	plat := platform.NewPlatform(5*time.Second, platform.JobPool{JobMap: map[platform.JobID]platform.Job{}})
+	plat.ShardWorkers = 4
+	_ = plat.AddJob(&shardedJob{JobWrapper: newWrapper()})
+	_ = plat.AddJob(&orderJob{JobWrapper: newWrapper(), misordered: &misordered})
+
+	pipe, err := plat.NewPipeline(ctx, []platform.JobID{"order"}, len(file.Table))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = plat.StartPipeline(ctx, pipe); err != nil {
+		t.Fatal(err)
+	}
+	if misordered != 0 {
+		t.Fatalf("%d rows received out of order", misordered)
+	}
+}
+
+// stoppingBroadcaster - считает Stop, как будто подписки живут вне процесса
+type stoppingBroadcaster struct {
+	*broadcaster.Broadcaster[platform.JobResult]
+	stops *int32
+}
+
+func (b *stoppingBroadcaster) Stop() { atomic.AddInt32(b.stops, 1) }
+func (b *stoppingBroadcaster) Create() platform.Broadcaster[platform.JobResult] {
+	return &stoppingBroadcaster{Broadcaster: &broadcaster.Broadcaster[platform.JobResult]{}, stops: b.stops}
+}
+
+func TestPipelineStopsSubscriptions(t *testing.T) {
+	file := &goexel.File[row]{Table: []*row{{id: 1}, {id: 2}}}
+	ctx := goexel.SetFileContext(context.Background(), file)
+
+	var stops int32
+	misordered := 0
+	plat := platform.NewPlatform(5*time.Second, platform.JobPool{JobMap: map[platform.JobID]platform.Job{}})
+	_ = plat.AddJob(&shardedJob{JobWrapper: &platform.JobWrapper{ResChan: &stoppingBroadcaster{stops: &stops}}})
+	_ = plat.AddJob(&orderJob{JobWrapper: &platform.JobWrapper{ResChan: &stoppingBroadcaster{stops: &stops}}, misordered: &misordered})
+
+	pipe, err := plat.NewPipeline(ctx, []platform.JobID{"order"}, len(file.Table))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = plat.StartPipeline(ctx, pipe); err != nil {
+		t.Fatal(err)
+	}
+	if stops != 2 {
+		t.Fatalf("expected both broadcasters to be stopped, got %d", stops)
+	}
+}
+
+// TestNotStartedPipelineStopsSubscriptions - подписки делаются уже при создании пайплайна,
+// поэтому останавливаются и у пайплайнов, которые не запустились или вышли из start раньше времени
+func TestNotStartedPipelineStopsSubscriptions(t *testing.T) {
+	file := &goexel.File[row]{Table: []*row{{id: 1}, {id: 2}}}
+	ctx := goexel.SetFileContext(context.Background(), file)
+
+	cases := []struct {
+		name string
+		run  func(t *testing.T, plat *platform.Platform)
+	}{
+		{"plan", func(t *testing.T, plat *platform.Platform) {
+			if _, err := plat.Plan(ctx, []platform.JobID{"order"}); err != nil {
+				t.Fatal(err)
+			}
+		}},
+		{"remove pending", func(t *testing.T, plat *platform.Platform) {
+			pipe, err := plat.NewPipeline(ctx, []platform.JobID{"order"}, len(file.Table))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err = plat.RemovePipeline(pipe.GetID()); err != nil {
+				t.Fatal(err)
+			}
+			// удаленный пайплайн уже не запустить
+			if err = plat.StartPipeline(ctx, pipe); err == nil {
+				t.Fatal("expected removed pipeline not to start")
+			}
+		}},
+		{"cancel pending", func(t *testing.T, plat *platform.Platform) {
+			pipe, err := plat.NewPipeline(ctx, []platform.JobID{"order"}, len(file.Table))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err = plat.CancelPipeline(pipe.GetID()); err != nil {
+				t.Fatal(err)
+			}
+		}},
+		{"cancelled before readers", func(t *testing.T, plat *platform.Platform) {
+			pipe, err := plat.NewPipeline(ctx, []platform.JobID{"order", "w"}, len(file.Table))
+			if err != nil {
+				t.Fatal(err)
+			}
+			// start выходит еще на врайтерах
+			cancelled, cancel := context.WithCancel(ctx)
+			cancel()
+			if err = plat.StartPipeline(cancelled, pipe); !errors.Is(err, context.Canceled) {
+				t.Fatalf("expected context.Canceled, got %v", err)
+			}
+		}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var stops int32
+			var order []platform.JobID
+			plat := platform.NewPlatform(5*time.Second, platform.JobPool{JobMap: map[platform.JobID]platform.Job{}})
+			_ = plat.AddJob(&shardedJob{JobWrapper: &platform.JobWrapper{ResChan: &stoppingBroadcaster{stops: &stops}}})
+			_ = plat.AddJob(&orderJob{JobWrapper: &platform.JobWrapper{ResChan: &stoppingBroadcaster{stops: &stops}}, misordered: new(int)})
+			_ = plat.AddJob(&writerJob{JobWrapper: newWrapper(), id: "w", order: &order})
+
+			c.run(t, plat)
+			if stops != 2 {
+				t.Fatalf("expected both broadcasters to be stopped, got %d", stops)
+			}
+		})
+	}
+}
+
+type typedReaderJob struct {
+	*platform.JobWrapper
+}
+
+func (j *typedReaderJob) Run(ctx context.Context) error { return nil }
+func (j *typedReaderJob) GetDepIDs() []platform.JobID   { return []platform.JobID{"sharded"} }
+func (j *typedReaderJob) GetID() platform.JobID         { return "typed" }
+func (j *typedReaderJob) GetType() platform.JobType     { return platform.Common }
+func (j *typedReaderJob) GetDepTypes() map[platform.JobID]reflect.Type {
+	return map[platform.JobID]reflect.Type{"sharded": platform.TypeOf[string]()}
+}
+func (j *typedReaderJob) Create() platform.Job {
+	return &typedReaderJob{JobWrapper: j.JobWrapper.Create()}
+}
+
+func TestDepTypesMismatch(t *testing.T) {
+	plat := platform.NewPlatform(time.Second, platform.JobPool{JobMap: map[platform.JobID]platform.Job{}})
+	_ = plat.AddJob(&shardedJob{JobWrapper: newWrapper()})
+	_ = plat.AddJob(&typedReaderJob{JobWrapper: newWrapper()})
+
+	_, err := plat.NewPipeline(context.Background(), []platform.JobID{"typed"}, 0)
+	var confErr *platform.ConfigurationError
+	if !errors.As(err, &confErr) || confErr.Kind != platform.ResultTypeError {
+		t.Fatalf("expected ResultTypeError, got %v", err)
+	}
+}
+
+type intJob struct {
+	*platform.JobWrapper
+	platform.Produces[int]
+}
+
+func (j *intJob) Run(ctx context.Context) error {
+	return platform.RunByLine(ctx, j.JobWrapper, func(c context.Context, _ *goexel.FileCellRegisterer, r *row) platform.JobResult {
+		return platform.JobResult{Res: int(r.id)}
+	})
+}
+
+func (j *intJob) GetDepIDs() []platform.JobID { return nil }
+func (j *intJob) GetID() platform.JobID       { return "int" }
+func (j *intJob) GetType() platform.JobType   { return platform.Common }
+func (j *intJob) Create() platform.Job {
+	return &intJob{JobWrapper: j.JobWrapper.Create()}
+}
+
+type depTypesJob struct {
+	*platform.JobWrapper
+	depTypes map[platform.JobID]reflect.Type
+}
+
+func (j *depTypesJob) Run(ctx context.Context) error { return nil }
+func (j *depTypesJob) GetDepIDs() []platform.JobID   { return []platform.JobID{"int"} }
+func (j *depTypesJob) GetID() platform.JobID         { return "reader" }
+func (j *depTypesJob) GetType() platform.JobType     { return platform.Common }
+func (j *depTypesJob) GetDepTypes() map[platform.JobID]reflect.Type {
+	return j.depTypes
+}
+func (j *depTypesJob) Create() platform.Job {
+	return &depTypesJob{JobWrapper: j.JobWrapper.Create(), depTypes: j.depTypes}
+}
+
+func TestDepTypes(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		depTypes map[platform.JobID]reflect.Type
+		kind     platform.ConfigurationErrorKind
+	}{
+		{name: "match", depTypes: map[platform.JobID]reflect.Type{"int": platform.TypeOf[int]()}},
+		{name: "int as bool", depTypes: map[platform.JobID]reflect.Type{"int": platform.TypeOf[bool]()}, kind: platform.ResultTypeError},
+		{name: "typo", depTypes: map[platform.JobID]reflect.Type{"itn": platform.TypeOf[bool]()}, kind: platform.UnknownDepTypeError},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			plat := platform.NewPlatform(time.Second, platform.JobPool{JobMap: map[platform.JobID]platform.Job{}})
+			_ = plat.AddJob(&intJob{JobWrapper: newWrapper()})
+			_ = plat.AddJob(&depTypesJob{JobWrapper: newWrapper(), depTypes: tc.depTypes})
+
+			_, err := plat.NewPipeline(context.Background(), []platform.JobID{"reader"}, 0)
+			if tc.kind == 0 {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			var confErr *platform.ConfigurationError
+			if !errors.As(err, &confErr) || confErr.Kind != tc.kind {
+				t.Fatalf("expected error kind %d, got %v", tc.kind, err)
+			}
+		})
+	}
+}
+
+type batchJob struct {
+	*platform.JobWrapper
+}
+
+func (j *batchJob) Run(ctx context.Context) error {
+	return platform.RunByItemBatch(ctx, j.JobWrapper, func(c context.Context, _ *goexel.FileCellRegisterer, rows []*row) platform.JobResult {
+		return platform.JobResult{Res: len(rows)}
+	})
+}
+
+func (j *batchJob) GetDepIDs() []platform.JobID          { return nil }
+func (j *batchJob) GetID() platform.JobID                { return "batch" }
+func (j *batchJob) GetType() platform.JobType            { return platform.Common }
+func (j *batchJob) GetGranularity() platform.Granularity { return platform.ByItemBatch }
+func (j *batchJob) Create() platform.Job {
+	return &batchJob{JobWrapper: j.JobWrapper.Create()}
+}
+
+type keyedJob struct {
+	*platform.JobWrapper
+	keyed      bool
+	batchSizes *[]int
+}
+
+func (j *keyedJob) Run(ctx context.Context) error {
+	batch := platform.DepChan[int](j.JobWrapper, "batch")
+	return platform.RunByLine(ctx, j.JobWrapper, func(c context.Context, _ *goexel.FileCellRegisterer, r *row) platform.JobResult {
+		size, err := batch.RecvFor(ctx, platform.RowIndex(c))
+		if err != nil {
+			return platform.JobResult{Err: err}
+		}
+		*j.batchSizes = append(*j.batchSizes, size)
+		return platform.JobResult{}
+	})
+}
+
+func (j *keyedJob) GetDepIDs() []platform.JobID { return []platform.JobID{"batch"} }
+func (j *keyedJob) GetID() platform.JobID       { return "keyed" }
+func (j *keyedJob) GetType() platform.JobType   { return platform.Common }
+func (j *keyedJob) ReadsByRow() bool            { return j.keyed }
+func (j *keyedJob) Create() platform.Job {
+	return &keyedJob{JobWrapper: j.JobWrapper.Create(), keyed: j.keyed, batchSizes: j.batchSizes}
+}
+
+func TestRecvForBatchDependency(t *testing.T) {
+	file := &goexel.File[row]{Table: []*row{{id: 1}, {id: 1}, {id: 1}, {id: 2}, {id: 3}, {id: 3}}}
+	ctx := goexel.SetFileContext(context.Background(), file)
+
+	var sizes []int
+	plat := platform.NewPlatform(time.Second, platform.JobPool{JobMap: map[platform.JobID]platform.Job{}})
+	_ = plat.AddJob(&batchJob{JobWrapper: newWrapper()})
+	_ = plat.AddJob(&keyedJob{JobWrapper: newWrapper(), keyed: true, batchSizes: &sizes})
+
+	pipe, err := plat.NewPipeline(ctx, []platform.JobID{"keyed"}, len(file.Table))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = plat.StartPipeline(ctx, pipe); err != nil {
+		t.Fatal(err)
+	}
+	expected := []int{3, 3, 3, 1, 2, 2}
+	if !reflect.DeepEqual(sizes, expected) {
+		t.Fatalf("expected %v, got %v", expected, sizes)
+	}
+}
+
+func TestGranularityMismatch(t *testing.T) {
+	var sizes []int
+	plat := platform.NewPlatform(time.Second, platform.JobPool{JobMap: map[platform.JobID]platform.Job{}})
+	_ = plat.AddJob(&batchJob{JobWrapper: newWrapper()})
+	_ = plat.AddJob(&keyedJob{JobWrapper: newWrapper(), batchSizes: &sizes})
+
+	_, err := plat.NewPipeline(context.Background(), []platform.JobID{"keyed"}, 0)
+	var confErr *platform.ConfigurationError
+	if !errors.As(err, &confErr) || confErr.Kind != platform.GranularityError {
+		t.Fatalf("expected GranularityError, got %v", err)
+	}
+}
+
+type partialReaderJob struct {
+	*platform.JobWrapper
+}
+
+func (j *partialReaderJob) Run(ctx context.Context) error {
+	sharded := platform.DepChan[int64](j.JobWrapper, "sharded")
+	return platform.RunByLine(ctx, j.JobWrapper, func(c context.Context, _ *goexel.FileCellRegisterer, r *row) platform.JobResult {
+		// последнюю строку не читаем вовсе, зависимость не должна на ней зависнуть
+		if r.id%2 == 0 {
+			return platform.JobResult{}
+		}
+		id, err := sharded.RecvFor(ctx, platform.RowIndex(c))
+		if err != nil || id != r.id {
+			return platform.JobResult{Err: errors.New("misaligned")}
+		}
+		return platform.JobResult{}
+	})
+}
+
+func (j *partialReaderJob) GetDepIDs() []platform.JobID { return []platform.JobID{"sharded"} }
+func (j *partialReaderJob) GetID() platform.JobID       { return "partial" }
+func (j *partialReaderJob) GetType() platform.JobType   { return platform.Common }
+func (j *partialReaderJob) Create() platform.Job {
+	return &partialReaderJob{JobWrapper: j.JobWrapper.Create()}
+}
+
+func TestPartialReaderDoesNotBlockDependency(t *testing.T) {
+	file := &goexel.File[row]{Table: []*row{{id: 1}, {id: 2}, {id: 3}, {id: 4}}}
+	ctx := goexel.SetFileContext(context.Background(), file)
+
+	plat := platform.NewPlatform(time.Second, platform.JobPool{JobMap: map[platform.JobID]platform.Job{}})
+	_ = plat.AddJob(&shardedJob{JobWrapper: newWrapper()})
+	_ = plat.AddJob(&partialReaderJob{JobWrapper: newWrapper()})
+
+	pipe, err := plat.NewPipeline(ctx, []platform.JobID{"partial"}, len(file.Table))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = plat.StartPipeline(ctx, pipe); err != nil {
+		t.Fatalf("pipeline must finish without hitting validation limit, got %v", err)
+	}
+}
+
+type skuRow struct {
+	Sku goxlsx.String `xlsx:"SKU"`
+}
+
+type skuJob struct {
+	*platform.JobWrapper
+	bad string
+}
+
+func (j *skuJob) Run(ctx context.Context) error {
+	return platform.RunByLine(ctx, j.JobWrapper, func(c context.Context, register *goexel.FileCellRegisterer, r *skuRow) platform.JobResult {
+		if r.Sku.Value == j.bad {
+			register.RegisterCommentByValue(&r.Sku, "плохой СКУ")
+		}
+		return platform.JobResult{Res: r.Sku.Value}
+	})
+}
+
+func (j *skuJob) GetDepIDs() []platform.JobID { return nil }
+func (j *skuJob) GetID() platform.JobID       { return "sku" }
+func (j *skuJob) GetType() platform.JobType   { return platform.Common }
+func (j *skuJob) Create() platform.Job {
+	return &skuJob{JobWrapper: j.JobWrapper.Create(), bad: j.bad}
+}
+
+type skuOrderJob struct {
+	*platform.JobWrapper
+	misordered *int
+}
+
+func (j *skuOrderJob) Run(ctx context.Context) error {
+	sku := j.Dependencies["sku"]
+	return platform.RunByLine(ctx, j.JobWrapper, func(c context.Context, _ *goexel.FileCellRegisterer, r *skuRow) platform.JobResult {
+		if res := sku.RecvFor(c, platform.RowIndex(c)); res.Err != nil || res.Res.(string) != r.Sku.Value {
+			*j.misordered++
+		}
+		return platform.JobResult{}
+	})
+}
+
+func (j *skuOrderJob) GetDepIDs() []platform.JobID { return []platform.JobID{"sku"} }
+func (j *skuOrderJob) GetID() platform.JobID       { return "sku_order" }
+func (j *skuOrderJob) GetType() platform.JobType   { return platform.Common }
+func (j *skuOrderJob) Create() platform.Job {
+	return &skuOrderJob{JobWrapper: j.JobWrapper.Create(), misordered: j.misordered}
+}
+
+// rowSkus - СКУ равен номеру строки в книге
+func rowSkus(rows int) []string {
+	res := make([]string, rows)
+	for i := range res {
+		res[i] = strconv.Itoa(i + 2)
+	}
+	return res
+}
+
+func TestStreamedFile(t *testing.T) {
+	file, err := goexel.NewStreamFile[skuRow](goexeltest.Bytes(t, goexeltest.SkuBook(t, rowSkus(2500)...)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !file.Streaming() || file.Len() != 2500 {
+		t.Fatalf("file is not streamed: %d rows", file.Len())
+	}
+	ctx := goexel.SetFileContext(context.Background(), file)
+
+	misordered := 0
+	plat := platform.NewPlatform(5*time.Second, platform.JobPool{JobMap: map[platform.JobID]platform.Job{}})
+	_ = plat.AddJob(&skuJob{JobWrapper: newWrapper(), bad: "2002"})
+	_ = plat.AddJob(&skuOrderJob{JobWrapper: newWrapper(), misordered: &misordered})
+
+	pipe, err := plat.NewPipeline(ctx, []platform.JobID{"sku_order"}, file.Len())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = plat.StartPipeline(ctx, pipe); err != nil {
+		t.Fatal(err)
+	}
+	if misordered != 0 {
+		t.Fatalf("%d rows received out of order", misordered)
+	}
+	if whole, err := plat.NeedsWholeTable(ctx, []platform.JobID{"sku_order"}); err != nil || whole {
+		t.Fatalf("line jobs must not need the whole table: %v", err)
+	}
+	if file.Table != nil {
+		t.Fatal("streamed file is materialized")
+	}
+
+	findings := file.CellRegister.Findings()
+	if len(findings) != 1 || findings[0].Cell != "A2002" || findings[0].Value != "2002" {
+		t.Fatalf("finding is not on the source row: %+v", findings)
+	}
+}
+
+func TestStreamedFileMaterializedForBatchJob(t *testing.T) {
+	file, err := goexel.NewStreamFile[row](goexeltest.Bytes(t, goexeltest.SkuBook(t, rowSkus(10)...)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := goexel.SetFileContext(context.Background(), file)
+
+	plat := platform.NewPlatform(5*time.Second, platform.JobPool{JobMap: map[platform.JobID]platform.Job{}})
+	_ = plat.AddJob(&batchJob{JobWrapper: newWrapper()})
+	pipe, err := plat.NewPipeline(ctx, []platform.JobID{"batch"}, file.Len())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = plat.StartPipeline(ctx, pipe); err != nil {
+		t.Fatal(err)
+	}
+	if file.Streaming() || len(file.Table) != 10 {
+		t.Fatalf("file is not materialized: %d rows", len(file.Table))
+	}
+	// заранее понятно, что потоком такой файл открывать незачем
+	if whole, err := plat.NeedsWholeTable(ctx, []platform.JobID{"batch"}); err != nil || !whole {
+		t.Fatalf("batch job must need the whole table: %v", err)
+	}
+}
+
+type planJob struct {
+	*platform.JobWrapper
+	id   platform.JobID
+	deps []platform.JobID
+}
+
+func (j *planJob) Run(ctx context.Context) error { return nil }
+func (j *planJob) GetDepIDs() []platform.JobID   { return j.deps }
+func (j *planJob) GetID() platform.JobID         { return j.id }
+func (j *planJob) GetType() platform.JobType     { return platform.Common }
+func (j *planJob) Create() platform.Job {
+	return &planJob{JobWrapper: j.JobWrapper.Create(), id: j.id, deps: j.deps}
+}
+
+func TestPlan(t *testing.T) {
+	var order []platform.JobID
+	plat := platform.NewPlatform(time.Second, platform.JobPool{JobMap: map[platform.JobID]platform.Job{}})
+	_ = plat.AddJob(&writerJob{JobWrapper: newWrapper(), id: "w", order: &order})
+	_ = plat.AddJob(&planJob{JobWrapper: newWrapper(), id: "a", deps: []platform.JobID{"b", "w"}})
+	_ = plat.AddJob(&planJob{JobWrapper: newWrapper(), id: "b"})
+
+	plan, err := plat.Plan(context.Background(), []platform.JobID{"a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := &platform.Plan{
+		Writers: []platform.PlanJob{{ID: "w", Type: platform.Writer, Implicit: true}},
+		Readers: []platform.PlanJob{{ID: "b", Type: platform.Common, Implicit: true}, {ID: "a", Type: platform.Common}},
+		Edges:   []platform.PlanEdge{{From: "a", To: "b", Subscription: true}, {From: "a", To: "w"}},
+	}
+	if !reflect.DeepEqual(plan, expected) {
+		t.Fatalf("unexpected plan: %+v", plan)
+	}
+	dot := plan.DOT()
+	for _, line := range []string{`"w" [fillcolor="#fde2a7", style="filled,dashed"]`, `"b" -> "a";`, `"w" -> "a" [style=dashed];`} {
+		if !strings.Contains(dot, line) {
+			t.Fatalf("dot has no %s:\n%s", line, dot)
+		}
+	}
+}
+
+func TestPlanHighlightsCycle(t *testing.T) {
+	plat := platform.NewPlatform(time.Second, platform.JobPool{JobMap: map[platform.JobID]platform.Job{}})
+	_ = plat.AddJob(&planJob{JobWrapper: newWrapper(), id: "a", deps: []platform.JobID{"x"}})
+	_ = plat.AddJob(&planJob{JobWrapper: newWrapper(), id: "x", deps: []platform.JobID{"y"}})
+	_ = plat.AddJob(&planJob{JobWrapper: newWrapper(), id: "y", deps: []platform.JobID{"x"}})
+
+	plan, err := plat.Plan(context.Background(), []platform.JobID{"a"})
+	var confErr *platform.ConfigurationError
+	if !errors.As(err, &confErr) || confErr.Kind != platform.CycleDependencyError {
+		t.Fatalf("expected CycleDependencyError, got %v", err)
+	}
+	if plan == nil || len(plan.Readers) != 3 {
+		t.Fatalf("expected plan with every job, got %+v", plan)
+	}
+	if cycle := plan.Cycle; len(cycle) != 3 || cycle[0] != cycle[2] || cycle[0] == "a" || cycle[1] == "a" {
+		t.Fatalf("unexpected cycle: %v", cycle)
+	}
+	// ребра a->x, x->y, y->x, в цикле два последних
+	if mermaid := plan.Mermaid(); !strings.Contains(mermaid, "linkStyle 1,2 ") {
+		t.Fatalf("cycle links are not highlighted:\n%s", mermaid)
+	}
+}
+
+func TestSubscribeJobAfterRun(t *testing.T) {
+	file := &goexel.File[row]{}
+	for i := 0; i < 50; i++ {
+		file.Table = append(file.Table, &row{id: int64(i)})
+	}
+	ctx := goexel.SetFileContext(context.Background(), file)
+
+	plat := platform.NewPlatform(5*time.Second, platform.JobPool{JobMap: map[platform.JobID]platform.Job{}})
+	_ = plat.AddJob(&shardedJob{JobWrapper: &platform.JobWrapper{
+		ResChan: broadcaster.NewBroadcaster[platform.JobResult](broadcaster.WithReplay(0)),
+	}})
+	pipe, err := plat.NewPipeline(ctx, []platform.JobID{"sharded"}, len(file.Table))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = plat.StartPipeline(ctx, pipe); err != nil {
+		t.Fatal(err)
+	}
+
+	// джоба уже закончилась, но с replay подписчик получает все ее результаты
+	results, err := plat.SubscribeJob(pipe.GetID(), "sharded")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := 0
+	for res := range results {
+		if res.Res.(int64) != int64(res.Row) {
+			t.Fatalf("row %d has result %v", res.Row, res.Res)
+		}
+		got++
+	}
+	if got != len(file.Table) {
+		t.Fatalf("expected %d results, got %d", len(file.Table), got)
+	}
+	if _, err = plat.SubscribeJob(pipe.GetID(), "missing"); err == nil {
+		t.Fatal("expected error for unknown job")
+	}
+}
+
+type cachedSkuJob struct {
+	*platform.JobWrapper
+	platform.Produces[string]
+	calls *int32
+}
+
+func (j *cachedSkuJob) Run(ctx context.Context) error {
+	return platform.RunByLine(ctx, j.JobWrapper, func(c context.Context, register *goexel.FileCellRegisterer, r *skuRow) platform.JobResult {
+		atomic.AddInt32(j.calls, 1)
+		if r.Sku.Value == "3" {
+			register.RegisterCommentByValue(&r.Sku, "плохой СКУ")
+		}
+		return platform.JobResult{Res: r.Sku.Value}
+	})
+}
+
+func (j *cachedSkuJob) GetDepIDs() []platform.JobID { return nil }
+func (j *cachedSkuJob) GetID() platform.JobID       { return "sku" }
+func (j *cachedSkuJob) GetType() platform.JobType   { return platform.Common }
+func (j *cachedSkuJob) IsRowIndependent() bool      { return true }
+func (j *cachedSkuJob) CacheVersion() string        { return "1" }
+func (j *cachedSkuJob) CacheFields() []string       { return []string{"SKU"} }
+func (j *cachedSkuJob) Create() platform.Job {
+	return &cachedSkuJob{JobWrapper: j.JobWrapper.Create(), calls: j.calls}
+}
+
+func TestResultCache(t *testing.T) {
+	var (
+		calls int32
+		store = cache.NewLRU(100)
+	)
+	run := func(skus ...string) ([]goexel.Finding, platform.CacheStats) {
+		file, err := goexel.NewFile[skuRow](goexeltest.Bytes(t, goexeltest.SkuBook(t, skus...)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		ctx := goexel.SetFileContext(context.Background(), file)
+
+		misordered := 0
+		plat := platform.NewPlatform(5*time.Second, platform.JobPool{JobMap: map[platform.JobID]platform.Job{}})
+		plat.ResultCache = store
+		_ = plat.AddJob(&cachedSkuJob{JobWrapper: newWrapper(), calls: &calls})
+		_ = plat.AddJob(&skuOrderJob{JobWrapper: newWrapper(), misordered: &misordered})
+		pipe, err := plat.NewPipeline(ctx, []platform.JobID{"sku_order"}, file.Len())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = plat.StartPipeline(ctx, pipe); err != nil {
+			t.Fatal(err)
+		}
+		// зависимая джоба должна получить все строки, даже взятые из кеша
+		if misordered != 0 {
+			t.Fatalf("%d rows received out of order", misordered)
+		}
+		stats, err := plat.GetCacheStats(pipe.GetID())
+		if err != nil || len(stats) != 1 {
+			t.Fatalf("unexpected cache stats %+v: %v", stats, err)
+		}
+		return file.CellRegister.Findings(), stats[0]
+	}
+
+	findings, stats := run("1", "2", "3", "4", "5")
+	if calls != 5 || stats.Misses != 5 || stats.Hits != 0 {
+		t.Fatalf("first run must compute every row: %d calls, %+v", calls, stats)
+	}
+	if len(findings) != 1 || findings[0].Cell != "A4" {
+		t.Fatalf("unexpected findings %+v", findings)
+	}
+
+	// строки переставили и одну исправили: считается только она, замечание переезжает вместе со строкой
+	findings, stats = run("3", "1", "2", "9", "5")
+	if calls != 6 || stats.Misses != 1 || stats.Hits != 4 {
+		t.Fatalf("only the changed row must be recomputed: %d calls, %+v", calls, stats)
+	}
+	if len(findings) != 1 || findings[0].Cell != "A2" || findings[0].Message != "плохой СКУ" || findings[0].JobID != "sku" {
+		t.Fatalf("cached finding is not replayed on the moved row: %+v", findings)
+	}
+}
@@ -0,0 +1,297 @@
+package platform
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	colorfmt "github.com/fatih/color"
+	"gitlab.ozon.ru/platform/errors"
+)
+
+// Plan - разрешенный граф пайплайна: что запустится, в каком порядке и кто кого читает
+type Plan struct {
+	// Writers - изменяющие джобы в порядке запуска
+	Writers []PlanJob
+	// Readers - обычные джобы, запускаются параллельно после всех врайтеров
+	Readers []PlanJob
+	// Edges - зависимости джоб, в том же порядке что и сами джобы
+	Edges []PlanEdge
+	// Cycle - цикл, из-за которого пайплайн не собрался, первая джоба повторяется в конце
+	Cycle []JobID
+}
+
+type PlanJob struct {
+	ID   JobID
+	Type JobType
+	// Implicit - джобу не просили, ее подтянул FetchJobDeps как зависимость
+	Implicit bool
+}
+
+// PlanEdge - джоба From зависит от To
+type PlanEdge struct {
+	From JobID
+	To   JobID
+	// Subscription - From читает результаты To через канал,
+	// от врайтеров результатов нет, зависимость от них только про порядок запуска
+	Subscription bool
+}
+
+// Plan - граф собранного пайплайна
+func (p *Pipeline) Plan() *Plan {
+	return newPlan(append(append([]Job{}, p.wJobs...), p.rJobs...), p.requested)
+}
+
+// Plan - план пайплайна из jobIDs без его создания в реестре.
+// Если пайплайн не собирается из-за цикла, вместе с ошибкой отдается план, где в Cycle путь цикла
+func (p *Platform) Plan(ctx context.Context, jobIDs []JobID) (*Plan, error) {
+	pipe, err := p.jobPool.createPipeline(ctx, jobIDs)
+	if err == nil {
+		// пайплайн только для плана и не запустится, его подписки сразу останавливаем
+		defer pipe.stop()
+		return pipe.Plan(), nil
+	}
+	err = errors.Wrap(err, "failed to create pipeline")
+	var confErr *ConfigurationError
+	if !errors.As(err, &confErr) || confErr.Kind != CycleDependencyError {
+		return nil, err
+	}
+
+	jobs, resolveErr := p.jobPool.resolveJobs(ctx, jobIDs)
+	if resolveErr != nil {
+		return nil, err
+	}
+	// топологического порядка у цикла нет, поэтому врайтеры и обычные джобы просто по id
+	ids := graphFromJobs(jobs).sortedIDs()
+	ordered := make([]Job, 0, len(ids))
+	for _, writers := range []bool{true, false} {
+		for _, id := range ids {
+			if (jobs[id].GetType() == Writer) == writers {
+				ordered = append(ordered, jobs[id])
+			}
+		}
+	}
+	plan := newPlan(ordered, jobIDs)
+	plan.Cycle = cyclePath(confErr.AdditionalInfo)
+	return plan, err
+}
+
+func newPlan(ordered []Job, requested []JobID) *Plan {
+	isRequested := make(map[JobID]bool, len(requested))
+	for _, jobID := range requested {
+		isRequested[jobID] = true
+	}
+	types := make(map[JobID]JobType, len(ordered))
+	for _, job := range ordered {
+		types[job.GetID()] = job.GetType()
+	}
+
+	res := &Plan{}
+	for _, job := range ordered {
+		planJob := PlanJob{ID: job.GetID(), Type: job.GetType(), Implicit: !isRequested[job.GetID()]}
+		if job.GetType() == Writer {
+			res.Writers = append(res.Writers, planJob)
+		} else {
+			res.Readers = append(res.Readers, planJob)
+		}
+		for _, depID := range job.GetDepIDs() {
+			res.Edges = append(res.Edges, PlanEdge{
+				From:         job.GetID(),
+				To:           depID,
+				Subscription: job.GetType() != Writer && types[depID] != Writer,
+			})
+		}
+	}
+	return res
+}
+
+// cyclePath - hasCycles кроме самого цикла отдает еще путь до него от джобы, с которой начался обход,
+// оставляем только цикл: от первой повторившейся джобы до ее повтора
+func cyclePath(info []JobID) []JobID {
+	for i, jobID := range info {
+		for j := i + 1; j < len(info); j++ {
+			if info[j] == jobID {
+				return info[i : j+1]
+			}
+		}
+	}
+	return info
+}
+
+// inCycle - ребро входит в цикл; в пути цикла каждая джоба зависит от предыдущей
+func (p *Plan) inCycle(edge PlanEdge) bool {
+	for i := 0; i+1 < len(p.Cycle); i++ {
+		if p.Cycle[i+1] == edge.From && p.Cycle[i] == edge.To {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Plan) jobInCycle(jobID JobID) bool {
+	for _, id := range p.Cycle {
+		if id == jobID {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Plan) jobs() []PlanJob {
+	return append(append([]PlanJob{}, p.Writers...), p.Readers...)
+}
+
+func (p *Plan) edgesFrom(jobID JobID) (res []PlanEdge) {
+	for _, edge := range p.Edges {
+		if edge.From == jobID {
+			res = append(res, edge)
+		}
+	}
+	return res
+}
+
+// Text - дерево: джобы по порядку запуска, под каждой ее зависимости
+func (p *Plan) Text() string {
+	sb := &strings.Builder{}
+	writeJob := func(prefix string, job PlanJob) {
+		sb.WriteString(prefix + string(job.ID))
+		if job.Implicit {
+			sb.WriteString(" (зависимость)")
+		}
+		sb.WriteString("\n")
+		edges := p.edgesFrom(job.ID)
+		for i, edge := range edges {
+			branch := "├── "
+			if i == len(edges)-1 {
+				branch = "└── "
+			}
+			line := string(edge.To)
+			if !edge.Subscription {
+				line += " (порядок)"
+			}
+			if p.inCycle(edge) {
+				line = colorfmt.RedString(line + " (цикл)")
+			}
+			fmt.Fprintf(sb, "%s%s%s\n", strings.Repeat(" ", len([]rune(prefix))), branch, line)
+		}
+	}
+
+	if len(p.Writers) != 0 {
+		sb.WriteString("Изменяющие валидации (поочередно):\n")
+		for i, job := range p.Writers {
+			writeJob(fmt.Sprintf("  %d. ", i+1), job)
+		}
+	}
+	if len(p.Readers) != 0 {
+		sb.WriteString("Валидации (параллельно):\n")
+		for _, job := range p.Readers {
+			writeJob("  - ", job)
+		}
+	}
+	if len(p.Cycle) != 0 {
+		cycle := make([]string, 0, len(p.Cycle))
+		for _, jobID := range p.Cycle {
+			cycle = append(cycle, string(jobID))
+		}
+		sb.WriteString(colorfmt.RedString("Цикл: %s", strings.Join(cycle, " -> ")) + "\n")
+	}
+	return sb.String()
+}
+
+// DOT - граф для Graphviz, стрелки идут от зависимости к зависящей джобе
+func (p *Plan) DOT() string {
+	sb := &strings.Builder{}
+	sb.WriteString("digraph pipeline {\n\trankdir=LR;\n\tnode [shape=box];\n")
+	for _, job := range p.jobs() {
+		var attrs, styles []string
+		if job.Type == Writer {
+			styles = append(styles, "filled")
+			attrs = append(attrs, `fillcolor="#fde2a7"`)
+		}
+		if job.Implicit {
+			styles = append(styles, "dashed")
+		}
+		if len(styles) != 0 {
+			attrs = append(attrs, fmt.Sprintf("style=%q", strings.Join(styles, ",")))
+		}
+		if p.jobInCycle(job.ID) {
+			attrs = append(attrs, "color=red")
+		}
+		fmt.Fprintf(sb, "\t%q", job.ID)
+		if len(attrs) != 0 {
+			fmt.Fprintf(sb, " [%s]", strings.Join(attrs, ", "))
+		}
+		sb.WriteString(";\n")
+	}
+	for _, edge := range p.Edges {
+		var attrs []string
+		if !edge.Subscription {
+			attrs = append(attrs, "style=dashed")
+		}
+		if p.inCycle(edge) {
+			attrs = append(attrs, "color=red", "penwidth=2")
+		}
+		fmt.Fprintf(sb, "\t%q -> %q", edge.To, edge.From)
+		if len(attrs) != 0 {
+			fmt.Fprintf(sb, " [%s]", strings.Join(attrs, ", "))
+		}
+		sb.WriteString(";\n")
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+// Mermaid - тот же граф в виде flowchart для Mermaid, у вершин синтетические id, имена джоб в подписях
+func (p *Plan) Mermaid() string {
+	sb := &strings.Builder{}
+	sb.WriteString("flowchart LR\n")
+
+	var (
+		nodeIDs                  = make(map[JobID]string)
+		writers, implicit, cycle []string
+	)
+	for i, job := range p.jobs() {
+		nodeID := fmt.Sprintf("j%d", i)
+		nodeIDs[job.ID] = nodeID
+		fmt.Fprintf(sb, "    %s[\"%s\"]\n", nodeID, strings.ReplaceAll(string(job.ID), `"`, "#quot;"))
+		if job.Type == Writer {
+			writers = append(writers, nodeID)
+		}
+		if job.Implicit {
+			implicit = append(implicit, nodeID)
+		}
+		if p.jobInCycle(job.ID) {
+			cycle = append(cycle, nodeID)
+		}
+	}
+
+	var cycleLinks []string
+	for i, edge := range p.Edges {
+		arrow := "-->"
+		if !edge.Subscription {
+			arrow = "-.->"
+		}
+		fmt.Fprintf(sb, "    %s %s %s\n", nodeIDs[edge.To], arrow, nodeIDs[edge.From])
+		if p.inCycle(edge) {
+			cycleLinks = append(cycleLinks, fmt.Sprint(i))
+		}
+	}
+
+	for _, class := range []struct {
+		name, style string
+		nodes       []string
+	}{
+		{"writer", "fill:#fde2a7", writers},
+		{"implicit", "stroke-dasharray:5 5", implicit},
+		{"cycle", "stroke:#d00,stroke-width:2px", cycle},
+	} {
+		if len(class.nodes) == 0 {
+			continue
+		}
+		fmt.Fprintf(sb, "    classDef %s %s\n    class %s %s\n", class.name, class.style, strings.Join(class.nodes, ","), class.name)
+	}
+	if len(cycleLinks) != 0 {
+		fmt.Fprintf(sb, "    linkStyle %s stroke:#d00,stroke-width:2px\n", strings.Join(cycleLinks, ","))
+	}
+	return sb.String()
+}
@@ -2,6 +2,8 @@ package platform
 
 import (
 	"context"
+	"runtime"
+	"sort"
 	"sync"
 	"time"
 
@@ -17,18 +19,24 @@ type Creator[T any] interface {
 }
 
 type Platform struct {
-	ValidationLimit  time.Duration
-	jobPool          JobPool
-	mu               *sync.RWMutex
-	runningPipelines map[PipelineID]*Pipeline
+	ValidationLimit time.Duration
+	// ShardWorkers - сколько воркеров обрабатывают строки одной RowIndependent джобы
+	ShardWorkers int
+	// ResultCache - куда кешировать результаты строк Cacheable джоб, nil - не кешировать
+	ResultCache ResultStore
+	jobPool     JobPool
+	// mu - охраняет реестр пайплайнов и их статусы
+	mu        *sync.RWMutex
+	pipelines map[PipelineID]*Pipeline
 }
 
 func NewPlatform(ValidationLimit time.Duration, jobPool JobPool) *Platform {
 	return &Platform{
-		ValidationLimit:  ValidationLimit,
-		jobPool:          jobPool,
-		mu:               &sync.RWMutex{},
-		runningPipelines: map[PipelineID]*Pipeline{},
+		ValidationLimit: ValidationLimit,
+		ShardWorkers:    runtime.NumCPU(),
+		jobPool:         jobPool,
+		mu:              &sync.RWMutex{},
+		pipelines:       map[PipelineID]*Pipeline{},
 	}
 }
 
@@ -40,36 +48,88 @@ func (p *Platform) AddJob(j Job) error {
 	return nil
 }
 
+// Jobs - все джобы пула, отсортированные по id
+func (p *Platform) Jobs() []Runner {
+	res := make([]Runner, 0, len(p.jobPool.JobMap))
+	for _, job := range p.jobPool.JobMap {
+		res = append(res, job)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].GetID() < res[j].GetID()
+	})
+	return res
+}
+
+// SetJobDeadline - задает дедлайн джобе в пуле, по его истечении джоба отдает ErrSkipped на оставшиеся строки
+func (p *Platform) SetJobDeadline(jobID JobID, deadline time.Duration) error {
+	if _, exists := p.jobPool.JobMap[jobID]; !exists {
+		return errors.Errorf("no job with id %s", jobID)
+	}
+	if p.jobPool.Deadlines == nil {
+		p.jobPool.Deadlines = make(map[JobID]time.Duration)
+	}
+	p.jobPool.Deadlines[jobID] = deadline
+	return nil
+}
+
 func (p *Platform) NewPipeline(ctx context.Context, jobs []JobID, fileLen int) (*Pipeline, error) {
 	pipeline, err := p.jobPool.createPipeline(ctx, jobs)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to create pipeline")
 	}
 	pipeline.fileLen = fileLen
+	pipeline.workers = p.ShardWorkers
+	pipeline.caches = newRowCaches(p.ResultCache, pipeline.rJobs)
+	pipeline.id = newPipelineID()
+	pipeline.createdAt = time.Now()
 	p.mu.Lock()
-	p.runningPipelines[pipeline.GetID()] = pipeline
+	p.pipelines[pipeline.GetID()] = pipeline
 	p.mu.Unlock()
 	return pipeline, nil
 }
 
+// StartPipeline - синхронно прогоняет пайплайн, статус и время выполнения попадают в реестр платформы
 func (p *Platform) StartPipeline(ctx context.Context, pipe *Pipeline) error {
-	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
+	if p.ValidationLimit > 0 {
+		ctx, cancel = context.WithTimeout(ctx, p.ValidationLimit)
+		defer cancel()
+	}
+	if err := p.markStarted(pipe, cancel); err != nil {
+		return err
+	}
 
 	err := pipe.start(ctx)
 
-	p.mu.Lock()
-	delete(p.runningPipelines, pipe.GetID())
-	p.mu.Unlock()
+	p.markFinished(pipe, err)
 	return err
 }
 
 func (p *Platform) GetProgress(pipeID PipelineID) (res PipelineProgress, err error) {
 	p.mu.RLock()
-	pipe, exists := p.runningPipelines[pipeID]
+	pipe, exists := p.pipelines[pipeID]
 	p.mu.RUnlock()
 	if !exists {
-		return nil, errors.Errorf("no pipeline %s", pipeID)
+		return nil, errors.Wrap(ErrPipelineNotFound, string(pipeID))
 	}
 	return pipe.getProgress(), nil
 }
+
+// SubscribeJob - подписка на результаты джобы уже созданного или запущенного пайплайна, например для отладки
+// или сборщика отчетов. Все результаты с начала придут, только если у броадкастера джобы включен replay.
+// Канал нужно читать до закрытия, иначе джоба встанет на отправке
+func (p *Platform) SubscribeJob(pipeID PipelineID, jobID JobID) (chan JobResult, error) {
+	p.mu.RLock()
+	pipe, exists := p.pipelines[pipeID]
+	p.mu.RUnlock()
+	if !exists {
+		return nil, errors.Wrap(ErrPipelineNotFound, string(pipeID))
+	}
+	for _, job := range pipe.rJobs {
+		if job.GetID() == jobID {
+			return job.Subscribe(), nil
+		}
+	}
+	return nil, errors.Errorf("pipeline %s has no job %s to subscribe to", pipeID, jobID)
+}
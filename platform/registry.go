@@ -0,0 +1,188 @@
+package platform
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"gitlab.ozon.ru/platform/errors"
+)
+
+var (
+	// ErrPipelineNotFound - пайплайна с таким id нет в реестре платформы
+	ErrPipelineNotFound = errors.New("pipeline not found")
+)
+
+type PipelineStatus int8
+
+const (
+	// PipelinePending - пайплайн собран, но еще не запущен
+	PipelinePending PipelineStatus = iota
+	PipelineRunning
+	PipelineFinished
+	// PipelineFailed - пайплайн остановился с фатальной ошибкой или по таймауту
+	PipelineFailed
+	// PipelineCancelled - пайплайн остановили через CancelPipeline
+	PipelineCancelled
+)
+
+func (s PipelineStatus) String() string {
+	switch s {
+	case PipelinePending:
+		return "pending"
+	case PipelineRunning:
+		return "running"
+	case PipelineFinished:
+		return "finished"
+	case PipelineFailed:
+		return "failed"
+	case PipelineCancelled:
+		return "cancelled"
+	}
+	return "unknown"
+}
+
+// PipelineInfo - снимок состояния пайплайна из реестра
+type PipelineInfo struct {
+	ID         PipelineID
+	Status     PipelineStatus
+	CreatedAt  time.Time
+	StartedAt  time.Time
+	FinishedAt time.Time
+	Jobs       []JobID
+	// FinishedJobs - закончившиеся джобы в порядке завершения
+	FinishedJobs []JobID
+	Err          error
+}
+
+func newPipelineID() PipelineID {
+	return PipelineID(uuid.NewString())
+}
+
+// info - снимок пайплайна, вызывать под мьютексом платформы
+func (p *Pipeline) info() PipelineInfo {
+	jobs := make([]JobID, 0, len(p.wJobs)+len(p.rJobs))
+	for _, job := range p.wJobs {
+		jobs = append(jobs, job.GetID())
+	}
+	for _, job := range p.rJobs {
+		jobs = append(jobs, job.GetID())
+	}
+	p.jobsMu.Lock()
+	finished := append([]JobID(nil), p.finishedJobs...)
+	p.jobsMu.Unlock()
+	return PipelineInfo{
+		ID:           p.id,
+		Status:       p.status,
+		CreatedAt:    p.createdAt,
+		StartedAt:    p.startedAt,
+		FinishedAt:   p.finishedAt,
+		Jobs:         jobs,
+		FinishedJobs: finished,
+		Err:          p.err,
+	}
+}
+
+// ListPipelines - все пайплайны платформы в порядке создания
+func (p *Platform) ListPipelines() []PipelineInfo {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	res := make([]PipelineInfo, 0, len(p.pipelines))
+	for _, pipe := range p.pipelines {
+		res = append(res, pipe.info())
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].CreatedAt.Before(res[j].CreatedAt)
+	})
+	return res
+}
+
+// GetPipeline - состояние пайплайна по id
+func (p *Platform) GetPipeline(pipeID PipelineID) (PipelineInfo, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	pipe, exists := p.pipelines[pipeID]
+	if !exists {
+		return PipelineInfo{}, errors.Wrap(ErrPipelineNotFound, string(pipeID))
+	}
+	return pipe.info(), nil
+}
+
+// CancelPipeline - останавливает запущенный пайплайн, а еще не запущенный просто помечает отмененным
+func (p *Platform) CancelPipeline(pipeID PipelineID) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	pipe, exists := p.pipelines[pipeID]
+	if !exists {
+		return errors.Wrap(ErrPipelineNotFound, string(pipeID))
+	}
+	switch pipe.status {
+	case PipelinePending:
+		pipe.status = PipelineCancelled
+		pipe.finishedAt = time.Now()
+		// start уже не будет, подписки останавливаем сами
+		pipe.stop()
+	case PipelineRunning:
+		pipe.cancelled = true
+		pipe.cancel()
+	default:
+		return errors.Errorf("pipeline %s is already %s", pipeID, pipe.status)
+	}
+	return nil
+}
+
+// RemovePipeline - убирает из реестра завершенный или так и не запущенный пайплайн.
+// У незапущенного останавливаются подписки, сделанные при создании, как в CancelPipeline
+func (p *Platform) RemovePipeline(pipeID PipelineID) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	pipe, exists := p.pipelines[pipeID]
+	if !exists {
+		return errors.Wrap(ErrPipelineNotFound, string(pipeID))
+	}
+	if pipe.status == PipelineRunning {
+		return errors.Errorf("pipeline %s is still %s", pipeID, pipe.status)
+	}
+	if pipe.status == PipelinePending {
+		// отменяем, чтобы удаленный пайплайн уже нельзя было запустить
+		pipe.status = PipelineCancelled
+		pipe.stop()
+	}
+	delete(p.pipelines, pipeID)
+	return nil
+}
+
+// markStarted - переводит пайплайн в running и запоминает как его отменить
+func (p *Platform) markStarted(pipe *Pipeline, cancel context.CancelFunc) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if pipe.status != PipelinePending {
+		return errors.Errorf("pipeline %s can't be started: it is %s", pipe.id, pipe.status)
+	}
+	pipe.status = PipelineRunning
+	pipe.startedAt = time.Now()
+	pipe.cancel = cancel
+	return nil
+}
+
+func (p *Platform) markFinished(pipe *Pipeline, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	pipe.finishedAt = time.Now()
+	pipe.err = err
+	switch {
+	case pipe.cancelled:
+		pipe.status = PipelineCancelled
+	case err != nil:
+		pipe.status = PipelineFailed
+	default:
+		pipe.status = PipelineFinished
+	}
+}
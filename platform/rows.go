@@ -0,0 +1,106 @@
+package platform
+
+import (
+	"context"
+
+	"gitlab.ozon.ru/platform/errors"
+	"gitlab.ozon.ru/validator/goexel"
+)
+
+type Granularity int8
+
+const (
+	// ByLine - один результат на строку, как в RunByLine
+	ByLine Granularity = iota
+	// ByItemBatch - один результат на батч строк с одним ItemID, как в RunByItemBatch
+	ByItemBatch
+)
+
+// Granular - опциональный интерфейс для Runner, по умолчанию джоба считается построчной
+type Granular interface {
+	GetGranularity() Granularity
+}
+
+// KeyedReader - опциональный интерфейс для Runner: джоба читает зависимости через RecvFor,
+// поэтому ей не важно сколько результатов шлет зависимость
+type KeyedReader interface {
+	ReadsByRow() bool
+}
+
+func granularity(job Job) Granularity {
+	if g, ok := job.(Granular); ok {
+		return g.GetGranularity()
+	}
+	return ByLine
+}
+
+// checkGranularity - построчная джоба, читающая батчевую через Recv, получит результатов меньше чем строк
+func checkGranularity(job, dep Job) error {
+	if granularity(job) != ByLine || granularity(dep) != ByItemBatch {
+		return nil
+	}
+	if kr, ok := job.(KeyedReader); ok && kr.ReadsByRow() {
+		return nil
+	}
+	return &ConfigurationError{
+		Kind:           GranularityError,
+		AdditionalInfo: []JobID{job.GetID(), dep.GetID()},
+	}
+}
+
+const (
+	rowIndexKey ctxJobKey = 3
+	jobIDKey    ctxJobKey = 4
+)
+
+func withJobID(ctx context.Context, jobID JobID) context.Context {
+	return context.WithValue(ctx, jobIDKey, jobID)
+}
+
+// register - регистратор файла, подписывающий замечания джобой, которая сейчас выполняется
+func register[T any](ctx context.Context, file *goexel.File[T]) *goexel.FileCellRegisterer {
+	jobID, _ := ctx.Value(jobIDKey).(JobID)
+	return file.CellRegister.ForJob(string(jobID))
+}
+
+func withRowIndex(ctx context.Context, row int) context.Context {
+	return context.WithValue(ctx, rowIndexKey, row)
+}
+
+// RowIndex - индекс в File.Table строки (у батча - первой строки), которую сейчас обрабатывает джоба
+// работает с контекстом, который RunByLine и RunByItemBatch отдают в lineRunner/batchRunner
+func RowIndex(ctx context.Context) int {
+	row, _ := ctx.Value(rowIndexKey).(int)
+	return row
+}
+
+// RecvFor - результат зависимости для строки row
+// пропускает результаты по строкам до row, а результат батча отдает для каждой его строки
+// строки надо спрашивать по возрастанию, назад канал не отматывается
+func (c Chan) RecvFor(ctx context.Context, row int) JobResult {
+	if c.last == nil {
+		return JobResult{Err: errors.Wrap(ErrFatal, "receiving from unknown dependency")}
+	}
+	for {
+		if last := c.last; last.Span != 0 && last.Row <= row && row < last.Row+last.Span {
+			return *last
+		} else if last.Span != 0 && row < last.Row {
+			return JobResult{Err: errors.Wrapf(ErrFatal, "row %d already passed, dependency is on row %d", row, last.Row)}
+		}
+
+		res := c.Recv(ctx)
+		if res.Span == 0 {
+			// результат без привязки к строкам, сопоставить его не с чем
+			if res.Err == nil {
+				res.Err = errors.Wrap(ErrFatal, "dependency result has no row key")
+			}
+			return res
+		}
+		*c.last = res
+	}
+}
+
+// RecvFor - типизированный RecvFor
+func (c TypedChan[T]) RecvFor(ctx context.Context, row int) (res T, err error) {
+	return c.typed(c.Chan.RecvFor(ctx, row))
+}
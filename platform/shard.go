@@ -0,0 +1,137 @@
+package platform
+
+import (
+	"context"
+	"sync"
+
+	"gitlab.ozon.ru/platform/errors"
+	"gitlab.ozon.ru/validator/goexel"
+)
+
+// shardSize - сколько строк воркер берет за раз
+const shardSize = 256
+
+// RowIndependent - опциональный интерфейс для Runner
+// джоба не хранит состояние между строками и не читает зависимости, поэтому строки можно гнать параллельно
+type RowIndependent interface {
+	IsRowIndependent() bool
+}
+
+const jobWorkersKey ctxJobKey = 2
+
+func withJobWorkers(ctx context.Context, workers int) context.Context {
+	if workers <= 1 {
+		return ctx
+	}
+	return context.WithValue(ctx, jobWorkersKey, workers)
+}
+
+func jobWorkers(ctx context.Context) int {
+	if workers, ok := ctx.Value(jobWorkersKey).(int); ok {
+		return workers
+	}
+	return 1
+}
+
+// isShardable - параллелим только джобы без подписок, иначе порядок Recv поломается
+func isShardable(job Job) bool {
+	ri, ok := job.(RowIndependent)
+	if !ok || !ri.IsRowIndependent() {
+		return false
+	}
+	return len(job.GetDepIDs()) == 0
+}
+
+type shard struct {
+	from, to int
+	res      []JobResult
+	done     chan struct{}
+}
+
+// runByLineSharded - режет таблицу на шарды и гонит их пулом воркеров,
+// а подписчикам результаты отдаются строго в порядке строк, как в обычном RunByLine
+func runByLineSharded[T any](
+	ctx context.Context,
+	jw *JobWrapper,
+	file *goexel.File[T],
+	workers int,
+	lineRunner func(c context.Context, register *goexel.FileCellRegisterer, row *T) JobResult,
+) error {
+	jobCtx := JobContext(ctx)
+	reg := register(ctx, file)
+
+	shards := make([]*shard, 0, len(file.Table)/shardSize+1)
+	for from := 0; from < len(file.Table); from += shardSize {
+		to := from + shardSize
+		if to > len(file.Table) {
+			to = len(file.Table)
+		}
+		shards = append(shards, &shard{from: from, to: to, res: make([]JobResult, to-from), done: make(chan struct{})})
+	}
+
+	var (
+		queue = make(chan *shard)
+		stop  = make(chan struct{})
+		// не даем воркерам убегать далеко вперед отправки, иначе весь файл осядет в памяти
+		window = make(chan struct{}, 2*workers)
+		wg     = &sync.WaitGroup{}
+	)
+	defer func() {
+		close(stop)
+		wg.Wait()
+	}()
+
+	go func() {
+		defer close(queue)
+		for _, sh := range shards {
+			select {
+			case <-stop:
+				return
+			case window <- struct{}{}:
+			}
+			select {
+			case <-stop:
+				return
+			case queue <- sh:
+			}
+		}
+	}()
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for sh := range queue {
+				for i := sh.from; i < sh.to; i++ {
+					sh.res[i-sh.from] = lineRunner(withRowIndex(jobCtx, i), reg, file.Table[i])
+				}
+				close(sh.done)
+			}
+		}()
+	}
+
+	for _, sh := range shards {
+		select {
+		case <-ctx.Done():
+			return errors.Wrap(ErrFatal, ctx.Err().Error())
+		case <-sh.done:
+		}
+		for i, res := range sh.res {
+			if jobTimedOut(ctx) {
+				return jw.skipRest(ctx, sh.from+i, len(file.Table), nextLine)
+			}
+			res.Row, res.Span = sh.from+i, 1
+			if res.Err != nil {
+				if errors.Is(res.Err, ErrFatal) {
+					return res.Err
+				}
+			}
+			if err := jw.Send(ctx, res); err != nil {
+				return err
+			}
+			jw.setProgress(sh.from + i)
+		}
+		<-window
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package platform
+
+import (
+	"sort"
+	"time"
+
+	"gitlab.ozon.ru/platform/errors"
+)
+
+// BlockStater - броадкастер, который считает, сколько Send ждал каждого подписчика
+type BlockStater interface {
+	BlockStats() []SubscriberStats
+}
+
+// SubscriberStats - сколько отправитель ждал одного подписчика
+type SubscriberStats struct {
+	Subscriber string
+	Buffer     int
+	Sends      int64
+	// Blocked - сколько отправок не влезли в буфер и ждали, пока подписчик прочитает
+	Blocked     int64
+	BlockedTime time.Duration
+	MaxBlocked  time.Duration
+}
+
+// EdgeStats - ребро пайплайна: From отправляет результаты, To их читает
+type EdgeStats struct {
+	From JobID
+	To   JobID
+	SubscriberStats
+}
+
+// GetEdgeStats - сколько джобы ждали своих подписчиков, самые долгие ожидания первыми.
+// Можно звать и во время работы пайплайна, счетчики атомарные
+func (p *Platform) GetEdgeStats(pipeID PipelineID) ([]EdgeStats, error) {
+	p.mu.RLock()
+	pipe, exists := p.pipelines[pipeID]
+	p.mu.RUnlock()
+	if !exists {
+		return nil, errors.Wrap(ErrPipelineNotFound, string(pipeID))
+	}
+	return pipe.edgeStats(), nil
+}
+
+func (p *Pipeline) edgeStats() (res []EdgeStats) {
+	for _, job := range p.rJobs {
+		stater, ok := job.(BlockStater)
+		if !ok {
+			continue
+		}
+		for _, stats := range stater.BlockStats() {
+			res = append(res, EdgeStats{From: job.GetID(), To: JobID(stats.Subscriber), SubscriberStats: stats})
+		}
+	}
+	sort.SliceStable(res, func(i, j int) bool {
+		return res[i].BlockedTime > res[j].BlockedTime
+	})
+	return res
+}
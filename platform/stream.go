@@ -0,0 +1,105 @@
+package platform
+
+import (
+	"context"
+
+	"gitlab.ozon.ru/platform/errors"
+	"gitlab.ozon.ru/validator/goexel"
+)
+
+// WholeTable - опциональный интерфейс для Runner
+// джобе нужна вся таблица сразу, поэтому потоковый файл перед запуском пайплайна декодируется целиком.
+// Врайтерам и батчевым джобам таблица нужна всегда, им объявлять не надо
+type WholeTable interface {
+	NeedsWholeTable() bool
+}
+
+func needsWholeTable(job Job) bool {
+	if job.GetType() == Writer {
+		return true
+	}
+	if g, ok := job.(Granular); ok && g.GetGranularity() == ByItemBatch {
+		return true
+	}
+	w, ok := job.(WholeTable)
+	return ok && w.NeedsWholeTable()
+}
+
+// NeedsWholeTable - декодируется ли потоковый файл для пайплайна из jobIDs целиком, как в prepareStream.
+// Тогда открывать файл потоком незачем: это только лишний проход по листу ради Len
+func (p *Platform) NeedsWholeTable(ctx context.Context, jobIDs []JobID) (bool, error) {
+	jobs, err := p.jobPool.resolveJobs(ctx, jobIDs)
+	if err != nil {
+		return false, err
+	}
+	for _, job := range jobs {
+		if needsWholeTable(job) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// prepareStream - если файл в контексте потоковый, то либо подписывает на него всех читателей,
+// либо декодирует его целиком, когда кому-то нужна вся таблица. nil - стримить нечего
+func (p *Pipeline) prepareStream(ctx context.Context) (goexel.Streamer, error) {
+	stream := goexel.GetStreamerFromContext(ctx)
+	if stream == nil || !stream.Streaming() {
+		return nil, nil
+	}
+	for _, jobs := range [][]Job{p.wJobs, p.rJobs} {
+		for _, job := range jobs {
+			if needsWholeTable(job) {
+				return nil, stream.Materialize()
+			}
+		}
+	}
+
+	jobIDs := make([]string, 0, len(p.rJobs))
+	for _, job := range p.rJobs {
+		jobIDs = append(jobIDs, string(job.GetID()))
+	}
+	stream.Expect(jobIDs)
+	return stream, nil
+}
+
+// runByLineStream - RunByLine по пачкам строк потокового файла, результаты идут по мере чтения листа
+func runByLineStream[T any](
+	ctx context.Context,
+	jw *JobWrapper,
+	file *goexel.File[T],
+	lineRunner func(c context.Context, register *goexel.FileCellRegisterer, row *T) JobResult,
+) error {
+	jobID, _ := ctx.Value(jobIDKey).(JobID)
+	chunks, subscribed := file.Subscribe(string(jobID))
+	if !subscribed {
+		return errors.Wrapf(ErrFatal, "job %s is not subscribed to the file stream", jobID)
+	}
+
+	jobCtx := JobContext(ctx)
+	reg := register(ctx, file)
+	for chunk := range chunks {
+		chunkReg := reg.WithRowOffset(chunk.Offset)
+		for k, row := range chunk.Rows {
+			i := chunk.From + k
+			res := lineRunner(withRowIndex(jobCtx, i), chunkReg, row)
+			if jobTimedOut(ctx) {
+				return jw.skipRest(ctx, i, file.Len(), nextLine)
+			}
+			res.Row, res.Span = i, 1
+			if res.Err != nil {
+				if errors.Is(res.Err, ErrFatal) {
+					return res.Err
+				}
+			}
+			if err := jw.Send(ctx, res); err != nil {
+				return err
+			}
+			jw.setProgress(i)
+		}
+	}
+	if err := file.StreamErr(); err != nil {
+		return errors.Wrap(ErrFatal, err.Error())
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package platform
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"gitlab.ozon.ru/platform/errors"
+)
+
+// ResultTyper - опциональный интерфейс: джоба объявляет тип, который лежит в JobResult.Res
+type ResultTyper interface {
+	GetResultType() reflect.Type
+}
+
+// DepTyper - опциональный интерфейс: джоба объявляет какие типы ждет от своих зависимостей
+// createPipeline сверяет их с ResultTyper зависимостей и падает с ConfigurationError если не сходится
+type DepTyper interface {
+	GetDepTypes() map[JobID]reflect.Type
+}
+
+// TypeOf - reflect.Type для T, работает и для интерфейсов
+func TypeOf[T any]() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+// Produces - встраивается в джобу и объявляет тип ее результатов
+type Produces[T any] struct{}
+
+func (Produces[T]) GetResultType() reflect.Type {
+	return TypeOf[T]()
+}
+
+// TypedChan - канал зависимости, который сразу отдает результат нужного типа
+type TypedChan[T any] struct {
+	Chan
+	depID JobID
+}
+
+// DepChan - типизированный канал зависимости depID
+func DepChan[T any](jw *JobWrapper, depID JobID) TypedChan[T] {
+	return TypedChan[T]{Chan: jw.Dependencies[depID], depID: depID}
+}
+
+// Recv - ждет следующий результат зависимости
+// если тип не совпал, значит джоба соврала про свой тип, дальше ехать нельзя - возвращаем ErrFatal
+func (c TypedChan[T]) Recv(ctx context.Context) (res T, err error) {
+	return c.typed(c.Chan.Recv(ctx))
+}
+
+func (c TypedChan[T]) typed(jobRes JobResult) (res T, err error) {
+	if jobRes.Err != nil {
+		return res, jobRes.Err
+	}
+	res, ok := jobRes.Res.(T)
+	if !ok {
+		return res, errors.Wrapf(ErrFatal, "[%s] sent %T instead of %s", c.depID, jobRes.Res, TypeOf[T]())
+	}
+	return res, nil
+}
+
+// checkDepTypes - сверяет типы на ребре job -> dep
+func checkDepTypes(job Job, jobs map[JobID]Job) error {
+	depTyper, ok := job.(DepTyper)
+	if !ok {
+		return nil
+	}
+	depIDs := make(map[JobID]struct{}, len(job.GetDepIDs()))
+	for _, depID := range job.GetDepIDs() {
+		depIDs[depID] = struct{}{}
+	}
+	for depID, expected := range depTyper.GetDepTypes() {
+		// опечатка в ключе иначе молча выключит проверку типа
+		if _, declared := depIDs[depID]; !declared {
+			return &ConfigurationError{
+				Kind:           UnknownDepTypeError,
+				AdditionalInfo: []JobID{job.GetID(), depID},
+			}
+		}
+		dep, exists := jobs[depID]
+		if !exists {
+			continue
+		}
+		var actual reflect.Type
+		if resTyper, ok := dep.(ResultTyper); ok {
+			actual = resTyper.GetResultType()
+		}
+		if actual == nil || !actual.AssignableTo(expected) {
+			return &ConfigurationError{
+				Kind:           ResultTypeError,
+				AdditionalInfo: []JobID{job.GetID(), depID},
+				Details:        fmt.Sprintf("ожидается %s, получено %v", expected, actual),
+			}
+		}
+	}
+	return nil
+}
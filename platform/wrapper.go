@@ -2,6 +2,7 @@ package platform
 
 import (
 	"context"
+	"sync/atomic"
 
 	"gitlab.ozon.ru/platform/errors"
 	"gitlab.ozon.ru/validator/goexel"
@@ -18,8 +19,25 @@ type Broadcaster[T any] interface {
 	Close()
 }
 
+// NamedBroadcaster - броадкастер, которому важно, кто подписывается: свой буфер, своя статистика
+type NamedBroadcaster[T any] interface {
+	SubNamed(name string) chan T
+}
+
+// Stopper - опциональный интерфейс броадкастера, подписки которого живут не только в каналах процесса, например в Redis.
+// Stop останавливает доставку всем подписчикам, пайплайн зовет его, когда все его джобы закончились
+type Stopper interface {
+	Stop()
+}
+
 type Chan struct {
 	ch chan JobResult
+	// last - последний прочитанный результат, нужен RecvFor
+	last *JobResult
+}
+
+func newChan(ch chan JobResult) Chan {
+	return Chan{ch: ch, last: &JobResult{}}
 }
 
 func (c Chan) Recv(ctx context.Context) JobResult {
@@ -46,6 +64,22 @@ func (j *JobWrapper) Subscribe() chan JobResult {
 	return j.ResChan.Sub()
 }
 
+// SubscribeAs - Subscribe от имени джобы subscriber, если броадкастер различает подписчиков
+func (j *JobWrapper) SubscribeAs(subscriber JobID) chan JobResult {
+	if named, ok := j.ResChan.(NamedBroadcaster[JobResult]); ok {
+		return named.SubNamed(string(subscriber))
+	}
+	return j.ResChan.Sub()
+}
+
+// BlockStats - сколько отправка результатов ждала каждого подписчика, nil если броадкастер не считает
+func (j *JobWrapper) BlockStats() []SubscriberStats {
+	if stater, ok := j.ResChan.(BlockStater); ok {
+		return stater.BlockStats()
+	}
+	return nil
+}
+
 // SetDependencyChan - запоминает канал в который будет писать зависимость с ID = depID
 func (j *JobWrapper) SetDependencyChan(depID JobID, ch Chan) {
 	j.Dependencies[depID] = ch
@@ -67,6 +101,13 @@ func (j *JobWrapper) Close() {
 	j.ResChan.Close()
 }
 
+// Stop - останавливает подписки на мои результаты, если броадкастер это умеет
+func (j *JobWrapper) Stop() {
+	if stopper, ok := j.ResChan.(Stopper); ok {
+		stopper.Stop()
+	}
+}
+
 func (j *JobWrapper) Create() (res *JobWrapper) {
 	res = new(JobWrapper)
 	res.Dependencies = map[JobID]Chan{}
@@ -74,8 +115,13 @@ func (j *JobWrapper) Create() (res *JobWrapper) {
 	return res
 }
 
+// GetProgress - сколько строк джоба уже обработала, читается из другой горутины, поэтому атомарно
 func (j *JobWrapper) GetProgress() int32 {
-	return j.progress
+	return atomic.LoadInt32(&j.progress)
+}
+
+func (j *JobWrapper) setProgress(row int) {
+	atomic.StoreInt32(&j.progress, int32(row))
 }
 
 func RunByLine[T any](
@@ -85,8 +131,21 @@ func RunByLine[T any](
 ) error {
 
 	file := goexel.GetFileFromContext[T](ctx)
+	lineRunner = cachedLines(ctx, lineRunner)
+	if file.Streaming() {
+		return runByLineStream(ctx, jw, file, lineRunner)
+	}
+	if workers := jobWorkers(ctx); workers > 1 {
+		return runByLineSharded(ctx, jw, file, workers, lineRunner)
+	}
+	jobCtx := JobContext(ctx)
+	reg := register(ctx, file)
 	for i, row := range file.Table {
-		res := lineRunner(ctx, file.CellRegister, row)
+		res := lineRunner(withRowIndex(jobCtx, i), reg, row)
+		if jobTimedOut(ctx) {
+			return jw.skipRest(ctx, i, len(file.Table), nextLine)
+		}
+		res.Row, res.Span = i, 1
 		if res.Err != nil {
 			if errors.Is(res.Err, ErrFatal) {
 				return res.Err
@@ -95,7 +154,7 @@ func RunByLine[T any](
 		if err := jw.Send(ctx, res); err != nil {
 			return err
 		}
-		jw.progress = int32(i)
+		jw.setProgress(i)
 	}
 	return nil
 }
@@ -110,16 +169,24 @@ func RunByItemBatch[T ItemIDGetter](
 	batchRunner func(c context.Context, register *goexel.FileCellRegisterer, rows []*T) JobResult,
 ) error {
 	file := goexel.GetFileFromContext[T](ctx)
+	if file.Streaming() {
+		return errors.Wrap(ErrFatal, "batch job needs the whole table, but the file is streamed")
+	}
 	if len(file.Table) == 0 {
 		return nil
 	}
+	jobCtx := JobContext(ctx)
+	reg := register(ctx, file)
 	end := 0
 	for i := 0; i < len(file.Table); {
-		for end+1 < len(file.Table) && (*file.Table[end]).GetItemID() == (*file.Table[end+1]).GetItemID() {
-			end++
+		end = batchEnd(file.Table, i)
+		res := batchRunner(withRowIndex(jobCtx, i), reg, file.Table[i:end])
+		if jobTimedOut(ctx) {
+			return jw.skipRest(ctx, i, len(file.Table), func(from int) int {
+				return batchEnd(file.Table, from)
+			})
 		}
-		end++
-		res := batchRunner(ctx, file.CellRegister, file.Table[i:end])
+		res.Row, res.Span = i, end-i
 		if res.Err != nil {
 			if errors.Is(res.Err, ErrFatal) {
 				return res.Err
@@ -129,7 +196,44 @@ func RunByItemBatch[T ItemIDGetter](
 			return err
 		}
 		i = end
-		jw.progress = int32(i)
+		jw.setProgress(i)
 	}
 	return nil
 }
+
+// batchEnd - индекс сразу за батчем строк с одинаковым ItemID, начинающимся с from
+func batchEnd[T ItemIDGetter](table []*T, from int) int {
+	end := from
+	for end+1 < len(table) && (*table[end]).GetItemID() == (*table[end+1]).GetItemID() {
+		end++
+	}
+	return end + 1
+}
+
+func nextLine(from int) int {
+	return from + 1
+}
+
+// skipRest - джоба вышла за дедлайн: отдаем подписчикам ErrSkipped на все строки начиная с from,
+// next отдает конец очередного результата, чтобы батчевые джобы и тут слали по результату на батч.
+// Каналы зависимостей дочитываем в фоне, иначе зависимости встанут на отправке нам
+func (j *JobWrapper) skipRest(ctx context.Context, from int, fileLen int, next func(from int) int) error {
+	for _, dep := range j.Dependencies {
+		drain(dep.ch)
+	}
+	for i := from; i < fileLen; i = next(i) {
+		if err := j.Send(ctx, JobResult{Err: ErrSkipped, Row: i, Span: next(i) - i}); err != nil {
+			return err
+		}
+	}
+	j.setProgress(fileLen)
+	return ErrJobTimeout
+}
+
+// drain - дочитывает канал в фоне, пока его не закроют
+func drain(ch chan JobResult) {
+	go func() {
+		for range ch {
+		}
+	}()
+}
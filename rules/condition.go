@@ -0,0 +1,236 @@
+package rules
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	goxlsx "gitlab.ozon.ru/express/platform/lib/go-xlsx"
+)
+
+// поддерживаемые операторы условий
+const (
+	opEq       = "="
+	opNe       = "!="
+	opGt       = ">"
+	opGte      = ">="
+	opLt       = "<"
+	opLte      = "<="
+	opIn       = "in"
+	opNotIn    = "not_in"
+	opRequired = "required"
+	opEmpty    = "empty"
+)
+
+var dateLayouts = []string{"2006-01-02", "02.01.2006"}
+
+// predicate - скомпилированная проверка строки: условие на колонку или выражение
+type predicate interface {
+	// holds - проверка правила: пустое или неизвестное значение ее не нарушает
+	holds(row reflect.Value) bool
+	// applies - проверка when: пустое или неизвестное значение значит, что правило к строке не относится
+	applies(row reflect.Value) bool
+	// cell - ячейка, к которой относится сообщение о нарушении
+	cell(row reflect.Value) goxlsx.Type
+}
+
+func compilePredicate(rowType reflect.Type, c Condition) (predicate, error) {
+	if c.Expr != "" {
+		if c.Column != "" || c.Op != "" {
+			return nil, errors.New("either expr or column with op should be set")
+		}
+		e, err := compileExpr(rowType, c.Expr)
+		if err != nil {
+			return nil, errors.Wrapf(err, "expr %q", c.Expr)
+		}
+		return e, nil
+	}
+	return compileCondition(rowType, c)
+}
+
+// condition - скомпилированное под конкретный тип строки условие
+type condition struct {
+	field    field
+	op       string
+	expected []interface{}
+}
+
+func compileCondition(rowType reflect.Type, c Condition) (*condition, error) {
+	f, err := fieldByColumn(rowType, c.Column)
+	if err != nil {
+		return nil, err
+	}
+	res := &condition{field: f, op: strings.ToLower(strings.TrimSpace(c.Op))}
+
+	var raw []interface{}
+	switch res.op {
+	case opRequired, opEmpty:
+		return res, nil
+	case opIn, opNotIn:
+		list, ok := c.Value.([]interface{})
+		if !ok {
+			return nil, errors.Errorf("%s: operator %s needs a list", c.Column, res.op)
+		}
+		raw = list
+	case opEq, opNe:
+		raw = []interface{}{c.Value}
+	case opGt, opGte, opLt, opLte:
+		if f.kind == kindString || f.kind == kindBool {
+			return nil, errors.Errorf("%s: operator %s is not supported for this column", c.Column, res.op)
+		}
+		raw = []interface{}{c.Value}
+	default:
+		return nil, errors.Errorf("%s: unknown operator %q", c.Column, c.Op)
+	}
+
+	for _, v := range raw {
+		val, err := convert(f.kind, v)
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s: bad value", c.Column)
+		}
+		res.expected = append(res.expected, val)
+	}
+	return res, nil
+}
+
+// convert - приводит значение из конфига к типу колонки
+func convert(kind fieldKind, v interface{}) (interface{}, error) {
+	switch kind {
+	case kindString:
+		return fmt.Sprint(v), nil
+	case kindNumber:
+		switch n := v.(type) {
+		case int:
+			return float64(n), nil
+		case int64:
+			return float64(n), nil
+		case float64:
+			return n, nil
+		case string:
+			return strconv.ParseFloat(strings.ReplaceAll(n, ",", "."), 64)
+		}
+	case kindBool:
+		switch b := v.(type) {
+		case bool:
+			return b, nil
+		case string:
+			return strings.EqualFold(b, "да") || strings.EqualFold(b, "true"), nil
+		}
+	case kindDate:
+		switch d := v.(type) {
+		case time.Time:
+			return d, nil
+		case string:
+			return parseDate(d)
+		}
+	}
+	return nil, errors.Errorf("can't use %v (%T)", v, v)
+}
+
+func parseDate(s string) (t time.Time, err error) {
+	for _, layout := range dateLayouts {
+		if t, err = time.Parse(layout, strings.TrimSpace(s)); err == nil {
+			return t, nil
+		}
+	}
+	return t, err
+}
+
+func (c *condition) cell(row reflect.Value) goxlsx.Type {
+	return c.field.cell(row)
+}
+
+// holds - выполняется ли условие для строки
+// пустая ячейка удовлетворяет любому условию кроме required, за обязательность отвечает только он
+func (c *condition) holds(row reflect.Value) bool {
+	return c.match(row, true)
+}
+
+// applies - условие как when: на пустой ячейке не выполняется ничего кроме empty
+func (c *condition) applies(row reflect.Value) bool {
+	return c.match(row, false)
+}
+
+// match - onEmpty - итог сравнения с пустой ячейкой, required и empty пустоту проверяют сами
+func (c *condition) match(row reflect.Value, onEmpty bool) bool {
+	actual, empty := c.field.value(row)
+	switch c.op {
+	case opRequired:
+		return !empty
+	case opEmpty:
+		return empty
+	}
+	if empty {
+		return onEmpty
+	}
+
+	switch c.op {
+	case opEq:
+		return compare(actual, c.expected[0]) == 0
+	case opNe:
+		return compare(actual, c.expected[0]) != 0
+	case opGt:
+		return compare(actual, c.expected[0]) > 0
+	case opGte:
+		return compare(actual, c.expected[0]) >= 0
+	case opLt:
+		return compare(actual, c.expected[0]) < 0
+	case opLte:
+		return compare(actual, c.expected[0]) <= 0
+	case opIn, opNotIn:
+		in := false
+		for _, exp := range c.expected {
+			if compare(actual, exp) == 0 {
+				in = true
+				break
+			}
+		}
+		return in == (c.op == opIn)
+	}
+	return false
+}
+
+// compare - значения уже приведены к одному типу при компиляции
+func compare(a, b interface{}) int {
+	switch av := a.(type) {
+	case float64:
+		bv := b.(float64)
+		switch {
+		case av < bv:
+			return -1
+		case av > bv:
+			return 1
+		}
+		return 0
+	case time.Time:
+		bv := b.(time.Time)
+		switch {
+		case av.Before(bv):
+			return -1
+		case av.After(bv):
+			return 1
+		}
+		return 0
+	case bool:
+		if av == b.(bool) {
+			return 0
+		}
+		return 1
+	case string:
+		return strings.Compare(strings.TrimSpace(av), strings.TrimSpace(b.(string)))
+	}
+	return 1
+}
+
+func (c Condition) String() string {
+	if c.Expr != "" {
+		return c.Expr
+	}
+	if c.Value == nil {
+		return fmt.Sprintf("%s %s", c.Column, c.Op)
+	}
+	return fmt.Sprintf("%s %s %v", c.Column, c.Op, c.Value)
+}
@@ -0,0 +1,119 @@
+package rules
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gitlab.ozon.ru/validator/goexel"
+	"gitlab.ozon.ru/validator/platform"
+	"gopkg.in/yaml.v2"
+)
+
+// Config - набор правил, которые аналитики описывают в yaml/json без релиза
+type Config struct {
+	Rules []Rule `json:"rules" yaml:"rules"`
+}
+
+// Severity - насколько серьезно нарушение правила, с ней же замечание попадает в отчет
+type Severity = goexel.Severity
+
+const (
+	// SeverityError - строка считается невалидной, зависимые правила ее пропустят
+	SeverityError = goexel.SeverityError
+	// SeverityWarning - сообщение пишется, но строка остается валидной
+	SeverityWarning = goexel.SeverityWarning
+	// SeverityInfo - просто информация для категорийного менеджера
+	SeverityInfo = goexel.SeverityInfo
+)
+
+// Condition - проверка одной колонки: `Column Op Value`, либо выражение Expr над несколькими полями
+// колонку можно указать заголовком из тега xlsx или именем поля структуры
+type Condition struct {
+	Column string      `json:"column,omitempty" yaml:"column,omitempty"`
+	Op     string      `json:"op,omitempty"     yaml:"op,omitempty"`
+	Value  interface{} `json:"value,omitempty"  yaml:"value,omitempty"`
+	// Expr - например `PromoPrice <= Price`, синтаксис описан в expr.go
+	Expr string `json:"expr,omitempty" yaml:"expr,omitempty"`
+}
+
+// Rule - правило, из которого получается отдельная platform.Job
+//
+//	id: Скидка для механики X
+//	column: Скидка off, руб
+//	op: ">"
+//	value: 0
+//	when: {column: Промо механика, op: "=", value: X}
+//	message: Для механики X нужна скидка
+type Rule struct {
+	ID        platform.JobID `json:"id" yaml:"id"`
+	Condition `yaml:",inline"`
+	// When - правило проверяется только для строк, где выполнено это условие.
+	// В отличие от проверки, пустая ячейка в when значит, что правило к строке не относится (кроме op: empty)
+	When *Condition `json:"when,omitempty" yaml:"when,omitempty"`
+	// DependsOn - джобы, которые должны вернуть true для строки, иначе строка пропускается
+	DependsOn []platform.JobID `json:"depends_on,omitempty" yaml:"depends_on,omitempty"`
+	Severity  Severity         `json:"severity,omitempty"   yaml:"severity,omitempty"`
+	Message   string           `json:"message,omitempty"    yaml:"message,omitempty"`
+	// Target - колонка, в ячейку которой пишется сообщение, если пусто - комментарий к проверяемой ячейке
+	Target string `json:"target,omitempty" yaml:"target,omitempty"`
+}
+
+// Load - читает конфиг, format это "json" или "yaml"
+func Load(r io.Reader, format string) (*Config, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read rules")
+	}
+
+	cfg := &Config{}
+	switch format {
+	case "json":
+		err = json.Unmarshal(data, cfg)
+	case "yaml", "yml":
+		err = yaml.UnmarshalStrict(data, cfg)
+	default:
+		return nil, errors.Errorf("unknown rules format %q", format)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode rules")
+	}
+	return cfg, cfg.validate()
+}
+
+// LoadFile - читает конфиг, формат определяется по расширению
+func LoadFile(path string) (*Config, error) {
+	//nolint:gosec
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open rules")
+	}
+	defer f.Close()
+
+	return Load(f, strings.TrimPrefix(filepath.Ext(path), "."))
+}
+
+func (c *Config) validate() error {
+	ids := make(map[platform.JobID]struct{}, len(c.Rules))
+	for i, rule := range c.Rules {
+		if rule.ID == "" {
+			return errors.Errorf("rule #%d has no id", i+1)
+		}
+		if _, exists := ids[rule.ID]; exists {
+			return errors.Errorf("rule %s is declared twice", rule.ID)
+		}
+		ids[rule.ID] = struct{}{}
+
+		switch rule.Severity {
+		case "":
+			c.Rules[i].Severity = SeverityError
+		case SeverityError, SeverityWarning, SeverityInfo:
+		default:
+			return errors.Errorf("rule %s: unknown severity %q", rule.ID, rule.Severity)
+		}
+	}
+	return nil
+}
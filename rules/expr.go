@@ -0,0 +1,589 @@
+package rules
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+	goxlsx "gitlab.ozon.ru/express/platform/lib/go-xlsx"
+)
+
+// Выражения для проверок по нескольким полям строки, например
+//
+//	PromoPrice <= Price
+//	`Начало действия закупочной цены в промо` >= PromoDateFrom && !empty(PriceListID)
+//	in(`Тип промо`, "Скидка", "Кешбек") || Coefficient > 1.5
+//
+// Поле можно указать именем в структуре или заголовком колонки в обратных кавычках.
+// Пустая ячейка дает неизвестное значение: сравнения и арифметика с ним тоже неизвестны,
+// а неизвестный итог правило считает выполненным, а when - невыполненным, то есть правило к строке не относится.
+// Проверять пустоту нужно явно через empty().
+
+// expression - скомпилированное выражение над строкой
+type expression struct {
+	root node
+	// fields - поля в порядке упоминания, к первому пишем комментарий
+	fields []field
+}
+
+func compileExpr(rowType reflect.Type, src string) (*expression, error) {
+	tokens, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{rowType: rowType, tokens: tokens}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokEOF {
+		return nil, errors.Errorf("unexpected %q at %d", tok.text, tok.pos)
+	}
+	if root.kind() != kindBool {
+		return nil, errors.New("expression must be a condition")
+	}
+	if len(p.fields) == 0 {
+		return nil, errors.New("expression doesn't use any column")
+	}
+	return &expression{root: root, fields: p.fields}, nil
+}
+
+func (e *expression) holds(row reflect.Value) bool {
+	res, known := e.root.eval(row).(bool)
+	return !known || res
+}
+
+// applies - выражение как when: неизвестный итог значит, что правило к строке не относится
+func (e *expression) applies(row reflect.Value) bool {
+	res, known := e.root.eval(row).(bool)
+	return known && res
+}
+
+func (e *expression) cell(row reflect.Value) goxlsx.Type {
+	return e.fields[0].cell(row)
+}
+
+// ---------------------------------------------------------------- lexer ----------------------------------------------------------------
+
+type tokenKind int8
+
+const (
+	tokEOF tokenKind = iota
+	tokNumber
+	tokString
+	tokIdent
+	tokColumn
+	tokOp
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+// операторы, сначала двухсимвольные
+var operators = []string{"==", "!=", "<=", ">=", "&&", "||", "<", ">", "=", "!", "+", "-", "*", "/", "(", ")", ","}
+
+func lex(src string) (tokens []token, err error) {
+	for i := 0; i < len(src); {
+		r, size := utf8.DecodeRuneInString(src[i:])
+		switch {
+		case unicode.IsSpace(r):
+			i += size
+		// только ascii цифры, иначе на цифре вроде "１" цикл ниже не сдвинется с места
+		case r >= '0' && r <= '9':
+			start := i
+			for i < len(src) && (src[i] >= '0' && src[i] <= '9' || src[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokNumber, text: src[start:i], pos: start})
+		case r == '"' || r == '\'' || r == '`':
+			end := strings.IndexRune(src[i+size:], r)
+			if end < 0 {
+				return nil, errors.Errorf("unclosed %c at %d", r, i)
+			}
+			kind := tokString
+			if r == '`' {
+				kind = tokColumn
+			}
+			tokens = append(tokens, token{kind: kind, text: src[i+size : i+size+end], pos: i})
+			i += size + end + size
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i < len(src) {
+				r, size = utf8.DecodeRuneInString(src[i:])
+				if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' {
+					break
+				}
+				i += size
+			}
+			tokens = append(tokens, token{kind: tokIdent, text: src[start:i], pos: start})
+		default:
+			op := ""
+			for _, candidate := range operators {
+				if strings.HasPrefix(src[i:], candidate) {
+					op = candidate
+					break
+				}
+			}
+			if op == "" {
+				return nil, errors.Errorf("unexpected %q at %d", r, i)
+			}
+			tokens = append(tokens, token{kind: tokOp, text: op, pos: i})
+			i += len(op)
+		}
+	}
+	return append(tokens, token{kind: tokEOF, pos: len(src)}), nil
+}
+
+// ---------------------------------------------------------------- parser ----------------------------------------------------------------
+
+type parser struct {
+	rowType reflect.Type
+	tokens  []token
+	pos     int
+	fields  []field
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokEOF {
+		p.pos++
+	}
+	return tok
+}
+
+// accept - съедает оператор или ключевое слово, если следующий токен один из ops
+func (p *parser) accept(ops ...string) (string, bool) {
+	tok := p.peek()
+	if tok.kind != tokOp && tok.kind != tokIdent {
+		return "", false
+	}
+	for _, op := range ops {
+		if tok.text == op {
+			p.pos++
+			return op, true
+		}
+	}
+	return "", false
+}
+
+func (p *parser) expect(op string) error {
+	if _, ok := p.accept(op); !ok {
+		tok := p.peek()
+		return errors.Errorf("expected %q at %d, got %q", op, tok.pos, tok.text)
+	}
+	return nil
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if _, ok := p.accept("||", "or"); !ok {
+			return left, nil
+		}
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		if left, err = newLogical("||", left, right); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if _, ok := p.accept("&&", "and"); !ok {
+			return left, nil
+		}
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		if left, err = newLogical("&&", left, right); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (p *parser) parseNot() (node, error) {
+	if _, ok := p.accept("!", "not"); ok {
+		x, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		if x.kind() != kindBool {
+			return nil, errors.New("! needs a condition")
+		}
+		return &notNode{x: x}, nil
+	}
+	return p.parseCmp()
+}
+
+func (p *parser) parseCmp() (node, error) {
+	left, err := p.parseSum()
+	if err != nil {
+		return nil, err
+	}
+	op, ok := p.accept("==", "=", "!=", "<=", ">=", "<", ">")
+	if !ok {
+		return left, nil
+	}
+	right, err := p.parseSum()
+	if err != nil {
+		return nil, err
+	}
+	if op == "=" {
+		op = "=="
+	}
+	return newCompare(op, left, right)
+}
+
+func (p *parser) parseSum() (node, error) {
+	left, err := p.parseTerm()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.accept("+", "-")
+		if !ok {
+			return left, nil
+		}
+		right, err := p.parseTerm()
+		if err != nil {
+			return nil, err
+		}
+		if left, err = newArith(op, left, right); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (p *parser) parseTerm() (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.accept("*", "/")
+		if !ok {
+			return left, nil
+		}
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		if left, err = newArith(op, left, right); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if _, ok := p.accept("-"); ok {
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return newArith("-", &literal{val: float64(0), k: kindNumber}, x)
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokNumber:
+		n, err := strconv.ParseFloat(tok.text, 64)
+		if err != nil {
+			return nil, errors.Errorf("bad number %q at %d", tok.text, tok.pos)
+		}
+		return &literal{val: n, k: kindNumber}, nil
+	case tokString:
+		return &literal{val: tok.text, k: kindString}, nil
+	case tokColumn:
+		return p.field(tok)
+	case tokIdent:
+		switch tok.text {
+		case "true", "false":
+			return &literal{val: tok.text == "true", k: kindBool}, nil
+		}
+		if p.peek().text == "(" && p.peek().kind == tokOp {
+			return p.parseCall(tok)
+		}
+		return p.field(tok)
+	case tokOp:
+		if tok.text == "(" {
+			x, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			return x, p.expect(")")
+		}
+	}
+	if tok.kind == tokEOF {
+		return nil, errors.New("unexpected end of expression")
+	}
+	return nil, errors.Errorf("unexpected %q at %d", tok.text, tok.pos)
+}
+
+func (p *parser) field(tok token) (node, error) {
+	f, err := fieldByColumn(p.rowType, tok.text)
+	if err != nil {
+		return nil, errors.Wrapf(err, "at %d", tok.pos)
+	}
+	p.fields = append(p.fields, f)
+	return &fieldNode{f: f}, nil
+}
+
+// parseCall - функции: empty(x), present(x), in(x, a, b...), date("2023-01-31")
+func (p *parser) parseCall(name token) (node, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	var args []node
+	for p.peek().text != ")" || p.peek().kind != tokOp {
+		if len(args) != 0 {
+			if err := p.expect(","); err != nil {
+				return nil, err
+			}
+		}
+		arg, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+	}
+	p.next()
+
+	switch name.text {
+	case "empty", "present":
+		if len(args) != 1 {
+			return nil, errors.Errorf("%s needs exactly one argument", name.text)
+		}
+		return &emptyNode{x: args[0], negate: name.text == "present"}, nil
+	case "in":
+		if len(args) < 2 {
+			return nil, errors.New("in needs a value and a list")
+		}
+		var res node = &literal{val: false, k: kindBool}
+		for _, arg := range args[1:] {
+			cmp, err := newCompare("==", args[0], arg)
+			if err != nil {
+				return nil, err
+			}
+			if res, err = newLogical("||", res, cmp); err != nil {
+				return nil, err
+			}
+		}
+		return res, nil
+	case "date":
+		if len(args) != 1 {
+			return nil, errors.New("date needs one string argument")
+		}
+		lit, ok := args[0].(*literal)
+		if !ok || lit.k != kindString {
+			return nil, errors.New("date needs one string argument")
+		}
+		t, err := parseDate(lit.val.(string))
+		if err != nil {
+			return nil, errors.Wrapf(err, "bad date %q", lit.val)
+		}
+		return &literal{val: t, k: kindDate}, nil
+	}
+	return nil, errors.Errorf("unknown function %s at %d", name.text, name.pos)
+}
+
+// ---------------------------------------------------------------- nodes ----------------------------------------------------------------
+
+// node - узел выражения, eval отдает float64, string, bool, time.Time или nil если значение неизвестно
+type node interface {
+	eval(row reflect.Value) interface{}
+	kind() fieldKind
+}
+
+type literal struct {
+	val interface{}
+	k   fieldKind
+}
+
+func (l *literal) eval(reflect.Value) interface{} { return l.val }
+func (l *literal) kind() fieldKind                { return l.k }
+
+type fieldNode struct {
+	f field
+}
+
+func (n *fieldNode) eval(row reflect.Value) interface{} {
+	val, empty := n.f.value(row)
+	if empty {
+		return nil
+	}
+	return val
+}
+
+func (n *fieldNode) kind() fieldKind { return n.f.kind }
+
+type emptyNode struct {
+	x      node
+	negate bool
+}
+
+func (n *emptyNode) eval(row reflect.Value) interface{} {
+	return (n.x.eval(row) == nil) != n.negate
+}
+
+func (n *emptyNode) kind() fieldKind { return kindBool }
+
+type notNode struct {
+	x node
+}
+
+func (n *notNode) eval(row reflect.Value) interface{} {
+	if v, known := n.x.eval(row).(bool); known {
+		return !v
+	}
+	return nil
+}
+
+func (n *notNode) kind() fieldKind { return kindBool }
+
+type logicalNode struct {
+	op          string
+	left, right node
+}
+
+func newLogical(op string, left, right node) (node, error) {
+	if left.kind() != kindBool || right.kind() != kindBool {
+		return nil, errors.Errorf("%s needs conditions on both sides", op)
+	}
+	return &logicalNode{op: op, left: left, right: right}, nil
+}
+
+// eval - трехзначная логика: false && unknown = false, true || unknown = true
+func (n *logicalNode) eval(row reflect.Value) interface{} {
+	l, lKnown := n.left.eval(row).(bool)
+	if lKnown && l == (n.op == "||") {
+		return l
+	}
+	r, rKnown := n.right.eval(row).(bool)
+	if rKnown && r == (n.op == "||") {
+		return r
+	}
+	if !lKnown || !rKnown {
+		return nil
+	}
+	return r
+}
+
+func (n *logicalNode) kind() fieldKind { return kindBool }
+
+type compareNode struct {
+	op          string
+	left, right node
+}
+
+// newCompare - строковый литерал рядом с датой сразу разбираем как дату
+func newCompare(op string, left, right node) (node, error) {
+	var err error
+	if left, err = coerceDate(left, right.kind()); err != nil {
+		return nil, err
+	}
+	if right, err = coerceDate(right, left.kind()); err != nil {
+		return nil, err
+	}
+	if left.kind() != right.kind() {
+		return nil, errors.Errorf("can't compare values of different types with %s", op)
+	}
+	if (left.kind() == kindBool || left.kind() == kindString) && op != "==" && op != "!=" {
+		return nil, errors.Errorf("operator %s works only with numbers and dates", op)
+	}
+	return &compareNode{op: op, left: left, right: right}, nil
+}
+
+func coerceDate(n node, other fieldKind) (node, error) {
+	lit, ok := n.(*literal)
+	if !ok || lit.k != kindString || other != kindDate {
+		return n, nil
+	}
+	t, err := parseDate(lit.val.(string))
+	if err != nil {
+		return nil, errors.Wrapf(err, "bad date %q", lit.val)
+	}
+	return &literal{val: t, k: kindDate}, nil
+}
+
+func (n *compareNode) eval(row reflect.Value) interface{} {
+	l, r := n.left.eval(row), n.right.eval(row)
+	if l == nil || r == nil {
+		return nil
+	}
+	c := compare(l, r)
+	switch n.op {
+	case "==":
+		return c == 0
+	case "!=":
+		return c != 0
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	case ">":
+		return c > 0
+	default:
+		return c >= 0
+	}
+}
+
+func (n *compareNode) kind() fieldKind { return kindBool }
+
+type arithNode struct {
+	op          string
+	left, right node
+}
+
+func newArith(op string, left, right node) (node, error) {
+	if left.kind() != kindNumber || right.kind() != kindNumber {
+		return nil, errors.Errorf("operator %s works only with numbers", op)
+	}
+	return &arithNode{op: op, left: left, right: right}, nil
+}
+
+func (n *arithNode) eval(row reflect.Value) interface{} {
+	l, lKnown := n.left.eval(row).(float64)
+	r, rKnown := n.right.eval(row).(float64)
+	if !lKnown || !rKnown {
+		return nil
+	}
+	switch n.op {
+	case "+":
+		return l + r
+	case "-":
+		return l - r
+	case "*":
+		return l * r
+	default:
+		if r == 0 {
+			return nil
+		}
+		return l / r
+	}
+}
+
+func (n *arithNode) kind() fieldKind { return kindNumber }
@@ -0,0 +1,104 @@
+package rules
+
+import (
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	goxlsx "gitlab.ozon.ru/express/platform/lib/go-xlsx"
+)
+
+// fieldKind - к чему приводим значение ячейки, чтобы сравнивать
+type fieldKind int8
+
+const (
+	kindString fieldKind = iota
+	kindNumber
+	kindBool
+	kindDate
+)
+
+// field - поле строки T, найденное по заголовку колонки или имени
+type field struct {
+	name  string
+	index int
+	kind  fieldKind
+}
+
+type emptier interface {
+	IsEmpty() bool
+}
+
+// fieldByColumn - ищет поле сначала по тегу xlsx, потом по имени поля
+func fieldByColumn(rowType reflect.Type, column string) (field, error) {
+	column = strings.TrimSpace(column)
+	byName := -1
+	for i := 0; i < rowType.NumField(); i++ {
+		f := rowType.Field(i)
+		if strings.TrimSpace(f.Tag.Get("xlsx")) == column {
+			return newField(f, i)
+		}
+		if f.Name == column {
+			byName = i
+		}
+	}
+	if byName >= 0 {
+		return newField(rowType.Field(byName), byName)
+	}
+	return field{}, errors.Errorf("unknown column %q", column)
+}
+
+func newField(f reflect.StructField, index int) (field, error) {
+	if !reflect.PtrTo(f.Type).Implements(reflect.TypeOf((*goxlsx.Type)(nil)).Elem()) {
+		return field{}, errors.Errorf("field %s is not an xlsx value", f.Name)
+	}
+	value, exists := f.Type.FieldByName("Value")
+	if !exists {
+		return field{}, errors.Errorf("field %s has no value", f.Name)
+	}
+
+	res := field{name: f.Name, index: index}
+	switch value.Type.Kind() {
+	case reflect.String:
+		res.kind = kindString
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Float32, reflect.Float64:
+		res.kind = kindNumber
+	case reflect.Bool:
+		res.kind = kindBool
+	default:
+		if value.Type != reflect.TypeOf(time.Time{}) {
+			return field{}, errors.Errorf("field %s has unsupported type %s", f.Name, value.Type)
+		}
+		res.kind = kindDate
+	}
+	return res, nil
+}
+
+// cell - ячейка поля в строке, через нее регистрируем сообщения
+func (f field) cell(row reflect.Value) goxlsx.Type {
+	return row.Field(f.index).Addr().Interface().(goxlsx.Type)
+}
+
+// value - значение ячейки приведенное к string, float64, bool или time.Time, empty если ячейка пустая
+func (f field) value(row reflect.Value) (val interface{}, empty bool) {
+	cell := row.Field(f.index).Addr().Interface()
+	if e, ok := cell.(emptier); ok && e.IsEmpty() {
+		return nil, true
+	}
+
+	v := row.Field(f.index).FieldByName("Value")
+	switch f.kind {
+	case kindString:
+		return v.String(), false
+	case kindNumber:
+		if v.CanInt() {
+			return float64(v.Int()), false
+		}
+		return v.Float(), false
+	case kindBool:
+		return v.Bool(), false
+	default:
+		return v.Interface().(time.Time), false
+	}
+}
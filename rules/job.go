@@ -0,0 +1,161 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/pkg/errors"
+	"gitlab.ozon.ru/validator/goexel"
+	"gitlab.ozon.ru/validator/platform"
+)
+
+// Job - джоба, которая проверяет одно правило из конфига на каждой строке
+// результат - прошла ли строка правило, поэтому от нее можно зависеть как от обычной bool джобы
+type Job[T any] struct {
+	*platform.JobWrapper
+	platform.Produces[bool]
+
+	rule   Rule
+	check  predicate
+	when   predicate
+	target *field
+}
+
+// NewJob - компилирует правило под тип строки T
+func NewJob[T any](rule Rule, wrapper *platform.JobWrapper) (*Job[T], error) {
+	rowType := reflect.TypeOf((*T)(nil)).Elem()
+
+	check, err := compilePredicate(rowType, rule.Condition)
+	if err != nil {
+		return nil, errors.Wrapf(err, "rule %s", rule.ID)
+	}
+	j := &Job[T]{
+		JobWrapper: wrapper,
+		rule:       rule,
+		check:      check,
+	}
+	if rule.When != nil {
+		if j.when, err = compilePredicate(rowType, *rule.When); err != nil {
+			return nil, errors.Wrapf(err, "rule %s: when", rule.ID)
+		}
+	}
+	if rule.Target != "" {
+		target, err := fieldByColumn(rowType, rule.Target)
+		if err != nil {
+			return nil, errors.Wrapf(err, "rule %s: target", rule.ID)
+		}
+		j.target = &target
+	}
+	return j, nil
+}
+
+// NewExprJob - джоба из одного выражения без конфига, сообщение пишется в колонку target или комментарием
+func NewExprJob[T any](id platform.JobID, expr, message, target string, wrapper *platform.JobWrapper) (*Job[T], error) {
+	return NewJob[T](Rule{
+		ID:        id,
+		Condition: Condition{Expr: expr},
+		Severity:  SeverityError,
+		Message:   message,
+		Target:    target,
+	}, wrapper)
+}
+
+// Jobs - джобы для всех правил конфига, каждой достается своя копия wrapper
+func Jobs[T any](cfg *Config, wrapper *platform.JobWrapper) ([]platform.Job, error) {
+	res := make([]platform.Job, 0, len(cfg.Rules))
+	for _, rule := range cfg.Rules {
+		job, err := NewJob[T](rule, wrapper.Create())
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, job)
+	}
+	return res, nil
+}
+
+func (j *Job[T]) Run(ctx context.Context) (err error) {
+	deps := make([]platform.TypedChan[bool], 0, len(j.rule.DependsOn))
+	for _, depID := range j.rule.DependsOn {
+		deps = append(deps, platform.DepChan[bool](j.JobWrapper, depID))
+	}
+
+	return platform.RunByLine[T](ctx, j.JobWrapper, func(c context.Context, register *goexel.FileCellRegisterer, row *T) platform.JobResult {
+		for _, dep := range deps {
+			passed, err := dep.RecvFor(ctx, platform.RowIndex(c))
+			if err != nil {
+				if errors.Is(err, platform.ErrFatal) {
+					return platform.JobResult{Err: err}
+				}
+				return platform.JobResult{Err: platform.ErrSkipped}
+			}
+			if !passed {
+				return platform.JobResult{Err: platform.ErrSkipped}
+			}
+		}
+
+		rowVal := reflect.ValueOf(row).Elem()
+		if j.when != nil && !j.when.applies(rowVal) {
+			return platform.JobResult{Res: true}
+		}
+		if j.check.holds(rowVal) {
+			return platform.JobResult{Res: true}
+		}
+
+		j.register(register.WithSeverity(j.rule.Severity), rowVal)
+		return platform.JobResult{Res: j.rule.Severity != SeverityError}
+	})
+}
+
+func (j *Job[T]) register(register *goexel.FileCellRegisterer, row reflect.Value) {
+	message := j.rule.Message
+	if message == "" {
+		message = fmt.Sprintf("Не выполнено условие %s", j.rule.Condition)
+	}
+
+	cell := j.check.cell(row)
+	if j.target == nil {
+		register.RegisterCommentByValue(cell, message)
+		return
+	}
+	register.OnSheet(cell.GetSheetName()).RegisterCellValueByPosition([]string{message}, j.target.cell(row).GetColumnNumber(), cell.GetRowNumber())
+}
+
+func (j *Job[T]) GetDepIDs() []platform.JobID {
+	return j.rule.DependsOn
+}
+
+// GetDepTypes - от зависимостей правило ждет bool: прошла строка или нет
+func (j *Job[T]) GetDepTypes() map[platform.JobID]reflect.Type {
+	res := make(map[platform.JobID]reflect.Type, len(j.rule.DependsOn))
+	for _, depID := range j.rule.DependsOn {
+		res[depID] = platform.TypeOf[bool]()
+	}
+	return res
+}
+
+func (j *Job[T]) ReadsByRow() bool {
+	return true
+}
+
+func (j *Job[T]) IsRowIndependent() bool {
+	return true
+}
+
+func (j *Job[T]) GetID() platform.JobID {
+	return j.rule.ID
+}
+
+func (j *Job[T]) GetType() platform.JobType {
+	return platform.Common
+}
+
+func (j *Job[T]) Create() platform.Job {
+	return &Job[T]{
+		JobWrapper: j.JobWrapper.Create(),
+		rule:       j.rule,
+		check:      j.check,
+		when:       j.when,
+		target:     j.target,
+	}
+}
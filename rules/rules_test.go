@@ -0,0 +1,308 @@
+package rules
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+	goxlsx "gitlab.ozon.ru/express/platform/lib/go-xlsx"
+	"gitlab.ozon.ru/validator/broadcaster"
+	"gitlab.ozon.ru/validator/goexel"
+	"gitlab.ozon.ru/validator/platform"
+)
+
+type testRow struct {
+	Mechanics goxlsx.String  `xlsx:"Промо механика"`
+	Discount  goxlsx.Float64 `xlsx:"Скидка off, руб"`
+	DateFrom  goxlsx.Date    `xlsx:"Дата начала"`
+	Comment   goxlsx.String  `xlsx:"Ошибка"`
+}
+
+const testConfig = `
+rules:
+  - id: Скидка для механики X
+    column: Скидка off, руб
+    op: ">"
+    value: 0
+    when: {column: Промо механика, op: "=", value: X}
+    target: Ошибка
+  - id: Механика из списка
+    column: Mechanics
+    op: in
+    value: [X, Y]
+    severity: warning
+`
+
+func TestLoad(t *testing.T) {
+	cfg, err := Load(strings.NewReader(testConfig), "yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.Rules) != 2 {
+		t.Fatalf("expected 2 rules, got %d", len(cfg.Rules))
+	}
+	if cfg.Rules[0].Severity != SeverityError || cfg.Rules[1].Severity != SeverityWarning {
+		t.Fatalf("unexpected severities: %s, %s", cfg.Rules[0].Severity, cfg.Rules[1].Severity)
+	}
+	if cfg.Rules[0].When == nil || cfg.Rules[0].When.Column != "Промо механика" {
+		t.Fatalf("when condition is lost: %+v", cfg.Rules[0].When)
+	}
+
+	for _, rule := range cfg.Rules {
+		if _, err = NewJob[testRow](rule, nil); err != nil {
+			t.Fatalf("rule %s: %v", rule.ID, err)
+		}
+	}
+}
+
+func TestLoadDuplicateIDs(t *testing.T) {
+	_, err := Load(strings.NewReader(`{"rules": [{"id": "a", "column": "Ошибка", "op": "required"}, {"id": "a", "column": "Ошибка", "op": "required"}]}`), "json")
+	if err == nil {
+		t.Fatal("expected error for duplicated rule id")
+	}
+}
+
+func TestCompileCondition(t *testing.T) {
+	rowType := reflect.TypeOf(testRow{})
+	for _, tc := range []struct {
+		name  string
+		cond  Condition
+		valid bool
+	}{
+		{"number", Condition{Column: "Скидка off, руб", Op: ">=", Value: "10,5"}, true},
+		{"date", Condition{Column: "Дата начала", Op: "<", Value: "01.02.2023"}, true},
+		{"unknown column", Condition{Column: "Нет такой", Op: "required"}, false},
+		{"order on string", Condition{Column: "Промо механика", Op: ">", Value: "X"}, false},
+		{"in without list", Condition{Column: "Промо механика", Op: "in", Value: "X"}, false},
+		{"bad number", Condition{Column: "Скидка off, руб", Op: "=", Value: "много"}, false},
+		{"unknown op", Condition{Column: "Скидка off, руб", Op: "~", Value: 1}, false},
+	} {
+		_, err := compileCondition(rowType, tc.cond)
+		if (err == nil) != tc.valid {
+			t.Errorf("%s: valid=%v, err=%v", tc.name, tc.valid, err)
+		}
+	}
+}
+
+// newTestFile - файл testRow, в каждой строке механика и скидка, пустая строка - пустая ячейка
+func newTestFile(t *testing.T, rows ...[2]string) *goexel.File[testRow] {
+	t.Helper()
+	book := excelize.NewFile()
+	for i, header := range []string{"Промо механика", "Скидка off, руб", "Дата начала", "Ошибка"} {
+		cell, _ := excelize.CoordinatesToCellName(i+1, 1)
+		_ = book.SetCellStr("Sheet1", cell, header)
+	}
+	for i, row := range rows {
+		for j, value := range row {
+			if value == "" {
+				continue
+			}
+			cell, _ := excelize.CoordinatesToCellName(j+1, i+2)
+			_ = book.SetCellStr("Sheet1", cell, value)
+		}
+	}
+	buf, err := book.WriteToBuffer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	file, err := goexel.NewFile[testRow](buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+func TestConditionHolds(t *testing.T) {
+	file := newTestFile(t, [2]string{"X", "10"}, [2]string{"", "0"}, [2]string{"Y", ""})
+	rowType := reflect.TypeOf(testRow{})
+	for _, tc := range []struct {
+		name    string
+		cond    Condition
+		row     int
+		holds   bool
+		applies bool
+	}{
+		{"equal", Condition{Column: "Промо механика", Op: "=", Value: "X"}, 0, true, true},
+		{"equal on empty", Condition{Column: "Промо механика", Op: "=", Value: "X"}, 1, true, false},
+		{"not equal on empty", Condition{Column: "Промо механика", Op: "!=", Value: "X"}, 1, true, false},
+		{"not in on empty", Condition{Column: "Промо механика", Op: "not_in", Value: []interface{}{"X"}}, 1, true, false},
+		{"in", Condition{Column: "Промо механика", Op: "in", Value: []interface{}{"X", "Y"}}, 2, true, true},
+		{"greater", Condition{Column: "Скидка off, руб", Op: ">", Value: 0}, 1, false, false},
+		{"greater on empty", Condition{Column: "Скидка off, руб", Op: ">", Value: 0}, 2, true, false},
+		{"required", Condition{Column: "Скидка off, руб", Op: "required"}, 1, true, true},
+		{"required on empty", Condition{Column: "Скидка off, руб", Op: "required"}, 2, false, false},
+		{"empty", Condition{Column: "Промо механика", Op: "empty"}, 1, true, true},
+		{"empty on value", Condition{Column: "Промо механика", Op: "empty"}, 0, false, false},
+	} {
+		cond, err := compileCondition(rowType, tc.cond)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		row := reflect.ValueOf(file.Table[tc.row]).Elem()
+		if holds := cond.holds(row); holds != tc.holds {
+			t.Errorf("%s: holds=%v, expected %v", tc.name, holds, tc.holds)
+		}
+		if applies := cond.applies(row); applies != tc.applies {
+			t.Errorf("%s: applies=%v, expected %v", tc.name, applies, tc.applies)
+		}
+	}
+}
+
+const guardedConfig = `
+rules:
+  - id: Скидка для механики X
+    column: Скидка off, руб
+    op: ">"
+    value: 0
+    when: {column: Промо механика, op: "=", value: X}
+    target: Ошибка
+  - id: Скидка обязательна для X
+    column: Скидка off, руб
+    op: required
+    when: {column: Промо механика, op: "=", value: X}
+    message: нет скидки
+`
+
+func TestJobRun(t *testing.T) {
+	cfg, err := Load(strings.NewReader(guardedConfig), "yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	jobs, err := Jobs[testRow](cfg, &platform.JobWrapper{ResChan: &broadcaster.Broadcaster[platform.JobResult]{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	plat := platform.NewPlatform(5*time.Second, platform.JobPool{JobMap: map[platform.JobID]platform.Job{}})
+	for _, job := range jobs {
+		if err = plat.AddJob(job); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	file := newTestFile(t,
+		[2]string{"X", "10"},
+		[2]string{"X", ""},
+		[2]string{"X", "0"},
+		// без механики правила к строке не относятся, хоть скидка и нулевая
+		[2]string{"", "0"},
+		[2]string{"Y", "0"},
+	)
+	ctx := goexel.SetFileContext(context.Background(), file)
+	pipe, err := plat.NewPipeline(ctx, []platform.JobID{"Скидка для механики X", "Скидка обязательна для X"}, file.Len())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = plat.StartPipeline(ctx, pipe); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, finding := range file.CellRegister.Findings() {
+		got = append(got, finding.JobID+" "+finding.Cell)
+	}
+	sort.Strings(got)
+	expected := []string{"Скидка для механики X D4", "Скидка обязательна для X B3"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected findings %v, got %v", expected, got)
+	}
+}
+
+type exprRow struct {
+	Price      goxlsx.Float64 `xlsx:"Закупочная регулярная цена без НДС, руб"`
+	PromoPrice goxlsx.Float64 `xlsx:"Закупка в промо без НДС, руб"`
+	DateFrom   goxlsx.Date    `xlsx:"Дата начала"`
+	PromoType  goxlsx.String  `xlsx:"Тип промо"`
+	UseInPromo goxlsx.Bool
+}
+
+func TestCompileExpr(t *testing.T) {
+	rowType := reflect.TypeOf(exprRow{})
+	for _, tc := range []struct {
+		expr  string
+		valid bool
+	}{
+		{"PromoPrice <= Price", true},
+		{"`Закупка в промо без НДС, руб` <= `Закупочная регулярная цена без НДС, руб` * 0.9", true},
+		{`DateFrom >= "2023-01-01" and !empty(PromoType)`, true},
+		{`in(PromoType, "Скидка", 'Кешбек') || UseInPromo`, true},
+		{`DateFrom > date("01.01.2023")`, true},
+		{"-PromoPrice < 0 or (Price = 1)", true},
+		{"PromoPrice", false},
+		{"PromoPrice <= PromoType", false},
+		{`PromoType > "a"`, false},
+		{"1 < 2", false},
+		{"Unknown > 1", false},
+		{"PromoPrice <= (Price", false},
+		{"unknown(PromoPrice)", false},
+		{`DateFrom > "вчера"`, false},
+		{`DateFrom > date()`, false},
+		{`DateFrom > date("01.01.2023", "02.01.2023")`, false},
+		{`DateFrom > date(PromoType)`, false},
+		// не ascii цифра - не число
+		{"Price > １", false},
+		{"Price > ٣", false},
+	} {
+		_, err := compileExpr(rowType, tc.expr)
+		if (err == nil) != tc.valid {
+			t.Errorf("%s: valid=%v, err=%v", tc.expr, tc.valid, err)
+		}
+	}
+}
+
+func TestExprApplies(t *testing.T) {
+	file := newTestFile(t, [2]string{"X", "10"}, [2]string{"", "0"}, [2]string{"X", ""})
+	rowType := reflect.TypeOf(testRow{})
+	for _, tc := range []struct {
+		expr    string
+		row     int
+		holds   bool
+		applies bool
+	}{
+		{`Mechanics = "X" && Discount > 5`, 0, true, true},
+		{`Mechanics = "X"`, 1, true, false},
+		{`Discount > 5`, 1, false, false},
+		{`Mechanics = "X" && Discount > 5`, 2, true, false},
+		{`Mechanics = "X" || Discount > 5`, 2, true, true},
+		{`empty(Mechanics)`, 1, true, true},
+	} {
+		e, err := compileExpr(rowType, tc.expr)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.expr, err)
+		}
+		row := reflect.ValueOf(file.Table[tc.row]).Elem()
+		if holds := e.holds(row); holds != tc.holds {
+			t.Errorf("%s on row %d: holds=%v, expected %v", tc.expr, tc.row, holds, tc.holds)
+		}
+		if applies := e.applies(row); applies != tc.applies {
+			t.Errorf("%s on row %d: applies=%v, expected %v", tc.expr, tc.row, applies, tc.applies)
+		}
+	}
+}
+
+func TestExprUnknownValues(t *testing.T) {
+	var (
+		unknown = &literal{val: nil, k: kindBool}
+		yes     = &literal{val: true, k: kindBool}
+		no      = &literal{val: false, k: kindBool}
+	)
+	for _, tc := range []struct {
+		name     string
+		node     node
+		expected interface{}
+	}{
+		{"false and unknown", &logicalNode{op: "&&", left: no, right: unknown}, false},
+		{"unknown and true", &logicalNode{op: "&&", left: unknown, right: yes}, nil},
+		{"unknown or true", &logicalNode{op: "||", left: unknown, right: yes}, true},
+		{"not unknown", &notNode{x: unknown}, nil},
+		{"compare with unknown", &compareNode{op: "<", left: &literal{val: nil, k: kindNumber}, right: &literal{val: 1.0, k: kindNumber}}, nil},
+		{"division by zero", &arithNode{op: "/", left: &literal{val: 1.0, k: kindNumber}, right: &literal{val: 0.0, k: kindNumber}}, nil},
+	} {
+		if res := tc.node.eval(reflect.Value{}); res != tc.expected {
+			t.Errorf("%s: expected %v, got %v", tc.name, tc.expected, res)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"path"
+	"time"
+
+	"github.com/fatih/color"
+	"gitlab.ozon.ru/validator/jobs"
+	"gitlab.ozon.ru/validator/pkg/validator"
+	"gitlab.ozon.ru/validator/server"
+	"google.golang.org/grpc"
+)
+
+// serve - http и gRPC режим валидатора: serve [--sku-catalog ...] [:8080] [/path/to/rules.yaml] [:8081]
+func serve(args []string) {
+	var skuCatalog string
+	fs := flag.NewFlagSet(path.Base(os.Args[0])+" serve", flag.ExitOnError)
+	fs.StringVar(&skuCatalog, "sku-catalog", "", skuCatalogUsage)
+	_ = fs.Parse(args)
+	args = fs.Args()
+
+	addr, grpcAddr := ":8080", ":8081"
+	if len(args) > 0 {
+		addr = args[0]
+	}
+	var rulesPath string
+	if len(args) > 1 {
+		rulesPath = args[1]
+	}
+	if len(args) > 2 {
+		grpcAddr = args[2]
+	}
+
+	plat, defaultJobs := newPlatform(rulesPath, newSkuCatalog(skuCatalog))
+	srv := server.NewServer[jobs.Entry](plat, defaultJobs)
+	srv.Retention = time.Hour
+
+	listener, err := net.Listen("tcp", grpcAddr)
+	if err != nil {
+		log.Fatalf("failed to listen %s: %s", grpcAddr, color.RedString(err.Error()))
+	}
+	grpcServer := grpc.NewServer(grpc.MaxRecvMsgSize(server.DefaultMaxUploadSize))
+	validator.RegisterValidatorServer(grpcServer, srv.GRPC())
+	go func() {
+		log.Fatal(grpcServer.Serve(listener))
+	}()
+
+	log.Printf(boundedStrLayout, fmt.Sprintf("validation service is listening on %s, gRPC on %s",
+		color.GreenString(addr), color.GreenString(grpcAddr)))
+	httpServer := &http.Server{
+		Addr:              addr,
+		Handler:           srv.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	log.Fatal(httpServer.ListenAndServe())
+}
@@ -0,0 +1,183 @@
+package server
+
+import (
+	"context"
+	"path"
+	"strings"
+	"time"
+
+	"gitlab.ozon.ru/platform/errors"
+	"gitlab.ozon.ru/validator/goexel"
+	"gitlab.ozon.ru/validator/pkg/validator"
+	"gitlab.ozon.ru/validator/platform"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// defaultWatchInterval - как часто WatchProgress шлет снимки, если клиент не попросил иначе
+const defaultWatchInterval = 500 * time.Millisecond
+
+type grpcServer[T any] struct {
+	validator.UnimplementedValidatorServer
+	s *Server[T]
+}
+
+// GRPC - тот же сервис в виде gRPC, валидации у него общие с http ручками
+func (s *Server[T]) GRPC() validator.ValidatorServer {
+	return &grpcServer[T]{s: s}
+}
+
+func (g *grpcServer[T]) Validate(_ context.Context, req *validator.ValidateRequest) (*validator.ValidateResponse, error) {
+	if len(req.GetFile()) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "empty file")
+	}
+	if limit := g.s.MaxUploadSize; limit > 0 && int64(len(req.GetFile())) > limit {
+		return nil, status.Errorf(codes.InvalidArgument, "file is larger than %d bytes", limit)
+	}
+	name := req.GetFileName()
+	if name == "" {
+		name = "validation.xlsx"
+	}
+	jobIDs := make([]platform.JobID, 0, len(req.GetJobIds()))
+	for _, jobID := range req.GetJobIds() {
+		jobIDs = append(jobIDs, platform.JobID(jobID))
+	}
+
+	g.s.cleanup()
+	pipeID, err := g.s.validate(name, goexel.DetectFormat(name), req.GetFile(), jobIDs)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return &validator.ValidateResponse{PipelineId: string(pipeID)}, nil
+}
+
+// WatchProgress - шлет снимок прогресса раз в интервал и по событию на каждую закончившуюся джобу,
+// последним событием идет PipelineCompleted
+func (g *grpcServer[T]) WatchProgress(req *validator.WatchProgressRequest, stream validator.Validator_WatchProgressServer) error {
+	interval := time.Duration(req.GetIntervalMs()) * time.Millisecond
+	if interval <= 0 {
+		interval = defaultWatchInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	pipeID := platform.PipelineID(req.GetPipelineId())
+	reported := 0
+	// sendJobs - события по джобам, которые закончились с прошлого раза
+	sendJobs := func(info platform.PipelineInfo) error {
+		for ; reported < len(info.FinishedJobs); reported++ {
+			if err := stream.Send(&validator.ProgressEvent{Event: &validator.ProgressEvent_JobCompleted{
+				JobCompleted: &validator.JobCompleted{JobId: string(info.FinishedJobs[reported])},
+			}}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for {
+		info, err := g.info(pipeID)
+		if err != nil {
+			return err
+		}
+		if err = sendJobs(info); err != nil {
+			return err
+		}
+		progress, _ := g.s.Platform.GetProgress(pipeID)
+		snapshot := &validator.ProgressSnapshot{Status: pipelineStatus(info.Status), Progress: make(map[string]float64, len(progress))}
+		for jobID, done := range progress {
+			snapshot.Progress[string(jobID)] = done
+		}
+		if err = stream.Send(&validator.ProgressEvent{Event: &validator.ProgressEvent_Snapshot{Snapshot: snapshot}}); err != nil {
+			return err
+		}
+
+		// результат еще собирается после того как пайплайн закончился, ждем и его
+		if g.s.get(pipeID).finished() {
+			// пайплайн мог закончиться уже после снимка, итог берем свежий
+			if info, err = g.info(pipeID); err != nil {
+				return err
+			}
+			if err = sendJobs(info); err != nil {
+				return err
+			}
+			completed := &validator.PipelineCompleted{Status: pipelineStatus(info.Status)}
+			if info.Err != nil {
+				completed.Error = info.Err.Error()
+			}
+			return stream.Send(&validator.ProgressEvent{Event: &validator.ProgressEvent_PipelineCompleted{PipelineCompleted: completed}})
+		}
+
+		select {
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (g *grpcServer[T]) GetResult(_ context.Context, req *validator.GetResultRequest) (*validator.GetResultResponse, error) {
+	pipeID := platform.PipelineID(req.GetPipelineId())
+	info, err := g.info(pipeID)
+	if err != nil {
+		return nil, err
+	}
+	res := g.s.get(pipeID)
+	if !res.finished() {
+		return nil, status.Errorf(codes.FailedPrecondition, "validation %s is still %s", pipeID, info.Status)
+	}
+	if info.Status != platform.PipelineFinished {
+		return nil, status.Errorf(codes.FailedPrecondition, "validation %s is %s: %v", pipeID, info.Status, info.Err)
+	}
+
+	resp := &validator.GetResultResponse{
+		Status:     pipelineStatus(info.Status),
+		Findings:   make([]*validator.Finding, 0, len(res.report.Findings)),
+		BySeverity: make(map[string]int32, len(res.report.BySeverity)),
+		ByJob:      make(map[string]int32, len(res.report.ByJob)),
+		HasErrors:  res.errors,
+		File:       res.file,
+		FileName:   strings.TrimSuffix(res.name, path.Ext(res.name)) + "_new_val_comm" + res.format.Ext(),
+	}
+	for _, finding := range res.report.Findings {
+		resp.Findings = append(resp.Findings, &validator.Finding{
+			JobId:    finding.JobID,
+			Sheet:    finding.Sheet,
+			Row:      int32(finding.Row),
+			Column:   int32(finding.Column),
+			Header:   finding.Header,
+			Cell:     finding.Cell,
+			Severity: string(finding.Severity),
+			Message:  finding.Message,
+			Value:    finding.Value,
+		})
+	}
+	for severity, count := range res.report.BySeverity {
+		resp.BySeverity[string(severity)] = int32(count)
+	}
+	for jobID, count := range res.report.ByJob {
+		resp.ByJob[jobID] = int32(count)
+	}
+	return resp, nil
+}
+
+func (g *grpcServer[T]) info(pipeID platform.PipelineID) (platform.PipelineInfo, error) {
+	info, err := g.s.Platform.GetPipeline(pipeID)
+	if err != nil || g.s.get(pipeID) == nil {
+		return platform.PipelineInfo{}, status.Error(codes.NotFound, errors.Wrap(platform.ErrPipelineNotFound, string(pipeID)).Error())
+	}
+	return info, nil
+}
+
+func pipelineStatus(s platform.PipelineStatus) validator.PipelineStatus {
+	switch s {
+	case platform.PipelineRunning:
+		return validator.PipelineStatus_PIPELINE_STATUS_RUNNING
+	case platform.PipelineFinished:
+		return validator.PipelineStatus_PIPELINE_STATUS_FINISHED
+	case platform.PipelineFailed:
+		return validator.PipelineStatus_PIPELINE_STATUS_FAILED
+	case platform.PipelineCancelled:
+		return validator.PipelineStatus_PIPELINE_STATUS_CANCELLED
+	}
+	return validator.PipelineStatus_PIPELINE_STATUS_PENDING
+}
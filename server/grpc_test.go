@@ -0,0 +1,84 @@
+package server_test
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"gitlab.ozon.ru/validator/broadcaster"
+	"gitlab.ozon.ru/validator/pkg/validator"
+	"gitlab.ozon.ru/validator/platform"
+	"gitlab.ozon.ru/validator/server"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestGRPC(t *testing.T) {
+	plat := platform.NewPlatform(5*time.Second, platform.JobPool{JobMap: map[platform.JobID]platform.Job{}})
+	_ = plat.AddJob(&emptySkuJob{JobWrapper: &platform.JobWrapper{ResChan: &broadcaster.Broadcaster[platform.JobResult]{}}})
+
+	listener := bufconn.Listen(1 << 20)
+	grpcServer := grpc.NewServer()
+	validator.RegisterValidatorServer(grpcServer, server.NewServer[skuRow](plat, []platform.JobID{"empty_sku"}).GRPC())
+	go func() { _ = grpcServer.Serve(listener) }()
+	defer grpcServer.Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	conn, err := grpc.DialContext(ctx, "bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return listener.Dial() }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	client := validator.NewValidatorClient(conn)
+
+	if _, err = client.Validate(ctx, &validator.ValidateRequest{File: newBook(t, "1"), JobIds: []string{"unknown"}}); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("expected invalid argument, got %v", err)
+	}
+	created, err := client.Validate(ctx, &validator.ValidateRequest{File: newBook(t, "1", ""), FileName: "promo.xlsx"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	watch, err := client.WatchProgress(ctx, &validator.WatchProgressRequest{PipelineId: created.GetPipelineId(), IntervalMs: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var (
+		completedJobs []string
+		completed     *validator.PipelineCompleted
+	)
+	for completed == nil {
+		event, err := watch.Recv()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if job := event.GetJobCompleted(); job != nil {
+			completedJobs = append(completedJobs, job.GetJobId())
+		}
+		completed = event.GetPipelineCompleted()
+	}
+	if completed.GetStatus() != validator.PipelineStatus_PIPELINE_STATUS_FINISHED || len(completedJobs) != 1 || completedJobs[0] != "empty_sku" {
+		t.Fatalf("unexpected events: %v, %v", completedJobs, completed)
+	}
+
+	result, err := client.GetResult(ctx, &validator.GetResultRequest{PipelineId: created.GetPipelineId()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.GetHasErrors() || len(result.GetFindings()) != 1 || result.GetFindings()[0].GetCell() != "A3" ||
+		result.GetFileName() != "promo_new_val_comm.xlsx" || len(result.GetFile()) == 0 {
+		t.Fatalf("unexpected result: %v", result)
+	}
+
+	if _, err = client.GetResult(ctx, &validator.GetResultRequest{PipelineId: "unknown"}); status.Code(err) != codes.NotFound {
+		t.Fatalf("expected not found, got %v", err)
+	}
+}
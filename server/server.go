@@ -0,0 +1,367 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"gitlab.ozon.ru/platform/errors"
+	"gitlab.ozon.ru/platform/tracer-go/logger"
+	"gitlab.ozon.ru/validator/goexel"
+	"gitlab.ozon.ru/validator/platform"
+)
+
+// DefaultMaxUploadSize - самый большой файл, который примем, если не задано иное
+const DefaultMaxUploadSize = 64 << 20
+
+// Server - http режим валидатора: принимает файл, гоняет его пайплайном платформы в фоне
+// и отдает статус, размеченный файл и json с замечаниями.
+// Каждый запрос получает свои копии джоб через JobPool.Get, так что загрузки друг другу не мешают
+type Server[T any] struct {
+	Platform *platform.Platform
+	// DefaultJobs - джобы для запросов, в которых список джоб не передали
+	DefaultJobs []platform.JobID
+	// MaxUploadSize - ограничение на размер файла, 0 - DefaultMaxUploadSize
+	MaxUploadSize int64
+	// Retention - сколько держать результаты закончившихся валидаций, 0 - пока их не удалят
+	Retention time.Duration
+
+	mu      sync.RWMutex
+	results map[platform.PipelineID]*result
+}
+
+// result - то, что остается от валидации, когда пайплайн закончился
+type result struct {
+	name   string
+	format goexel.Format
+	done   chan struct{}
+	file   []byte
+	report goexel.Report
+	errors bool
+}
+
+// finished - пайплайн закончился и результат собран
+func (r *result) finished() bool {
+	if r == nil {
+		return false
+	}
+	select {
+	case <-r.done:
+		return true
+	default:
+		return false
+	}
+}
+
+func NewServer[T any](plat *platform.Platform, defaultJobs []platform.JobID) *Server[T] {
+	return &Server[T]{
+		Platform:    plat,
+		DefaultJobs: defaultJobs,
+		results:     make(map[platform.PipelineID]*result),
+	}
+}
+
+// Handler - роутинг сервиса:
+//
+//	POST   /validations               - файл в теле или в поле file формы, джобы в ?jobs=a,b
+//	GET    /validations               - все валидации
+//	GET    /validations/{id}          - статус и прогресс по джобам
+//	GET    /validations/{id}/file     - размеченный файл
+//	GET    /validations/{id}/report   - замечания в json
+//	DELETE /validations/{id}          - отменяет идущую валидацию или удаляет закончившуюся
+func (s *Server[T]) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/validations", s.handleValidations)
+	mux.HandleFunc("/validations/", s.handleValidation)
+	return mux
+}
+
+func (s *Server[T]) handleValidations(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		s.upload(w, r)
+	case http.MethodGet:
+		infos := s.Platform.ListPipelines()
+		res := make([]statusResponse, 0, len(infos))
+		for _, info := range infos {
+			if s.get(info.ID) != nil {
+				res = append(res, s.status(info))
+			}
+		}
+		writeJSON(w, http.StatusOK, res)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, errors.Errorf("method %s is not allowed", r.Method))
+	}
+}
+
+func (s *Server[T]) handleValidation(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/validations/"), "/"), "/")
+	pipeID := platform.PipelineID(parts[0])
+	res := s.get(pipeID)
+	info, err := s.Platform.GetPipeline(pipeID)
+	if res == nil || err != nil {
+		writeError(w, http.StatusNotFound, errors.Wrap(platform.ErrPipelineNotFound, string(pipeID)))
+		return
+	}
+
+	switch {
+	case len(parts) == 1 && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, s.status(info))
+	case len(parts) == 1 && r.Method == http.MethodDelete:
+		s.delete(w, info)
+	case len(parts) == 2 && r.Method == http.MethodGet && (parts[1] == "file" || parts[1] == "report"):
+		if !res.finished() {
+			writeError(w, http.StatusConflict, errors.Errorf("validation %s is still %s", pipeID, info.Status))
+			return
+		}
+		if info, _ = s.Platform.GetPipeline(pipeID); info.Status != platform.PipelineFinished {
+			writeError(w, http.StatusConflict, errors.Errorf("validation %s is %s", pipeID, info.Status))
+			return
+		}
+		if parts[1] == "report" {
+			writeJSON(w, http.StatusOK, res.report)
+			return
+		}
+		w.Header().Set("Content-Type", contentType(res.format))
+		w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{
+			"filename": strings.TrimSuffix(res.name, path.Ext(res.name)) + "_new_val_comm" + res.format.Ext(),
+		}))
+		_, _ = w.Write(res.file)
+	default:
+		writeError(w, http.StatusNotFound, errors.Errorf("unknown route %s %s", r.Method, r.URL.Path))
+	}
+}
+
+func (s *Server[T]) upload(w http.ResponseWriter, r *http.Request) {
+	s.cleanup()
+
+	limit := s.MaxUploadSize
+	if limit <= 0 {
+		limit = DefaultMaxUploadSize
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, limit)
+
+	name, body, err := readUpload(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	format := goexel.Format(r.URL.Query().Get("format"))
+	if format == "" {
+		format = goexel.DetectFormat(name)
+	}
+
+	pipeID, err := s.validate(name, format, body, s.jobs(r))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	info, _ := s.Platform.GetPipeline(pipeID)
+	writeJSON(w, http.StatusAccepted, s.status(info))
+}
+
+// validate - декодирует файл, собирает пайплайн из копий джоб и запускает его в фоне.
+// Ошибки тут - ошибки запроса: битый файл или неизвестные джобы
+func (s *Server[T]) validate(name string, format goexel.Format, body []byte, jobIDs []platform.JobID) (platform.PipelineID, error) {
+	if len(jobIDs) == 0 {
+		jobIDs = s.DefaultJobs
+	}
+	// потоком есть смысл читать, только если пайплайну не нужна вся таблица
+	wholeTable, err := s.Platform.NeedsWholeTable(context.Background(), jobIDs)
+	if err != nil {
+		return "", err
+	}
+	var file *goexel.File[T]
+	if format == goexel.FormatXLSX && !wholeTable {
+		file, err = goexel.NewStreamFile[T](body, goexel.WithSummarySheet())
+	} else {
+		file, err = goexel.NewFileByFormat[T](body, format, goexel.WithSummarySheet())
+	}
+	if err != nil {
+		return "", err
+	}
+
+	// файл валидируется уже после ответа, поэтому контекст не от запроса
+	ctx := goexel.SetFileContext(context.Background(), file)
+	pipe, err := s.Platform.NewPipeline(ctx, jobIDs, file.Len())
+	if err != nil {
+		return "", err
+	}
+	res := &result{name: name, format: format, done: make(chan struct{})}
+	s.mu.Lock()
+	s.results[pipe.GetID()] = res
+	s.mu.Unlock()
+
+	go func() {
+		defer close(res.done)
+		if err := s.Platform.StartPipeline(ctx, pipe); err != nil {
+			logger.Errorf(ctx, "validation %s failed: %v", pipe.GetID(), err)
+			return
+		}
+		res.file = file.CellRegister.GetFileBytes()
+		res.report = file.CellRegister.GetReport()
+		res.errors = file.CellRegister.HasErrors()
+	}()
+	return pipe.GetID(), nil
+}
+
+// readUpload - файл либо в поле file multipart формы, либо прямо в теле, тогда имя из ?name=
+func readUpload(r *http.Request) (string, []byte, error) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "multipart/form-data" {
+		part, header, err := r.FormFile("file")
+		if err != nil {
+			return "", nil, errors.Wrap(err, "failed to read file field")
+		}
+		defer part.Close()
+		body, err := io.ReadAll(part)
+		if err != nil {
+			return "", nil, errors.Wrap(err, "failed to read file")
+		}
+		return header.Filename, body, nil
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return "", nil, errors.Wrap(err, "failed to read file")
+	}
+	if len(body) == 0 {
+		return "", nil, errors.New("empty file")
+	}
+	name := r.URL.Query().Get("name")
+	if name == "" {
+		name = "validation.xlsx"
+	}
+	return name, body, nil
+}
+
+// jobs - джобы из ?jobs=a,b или поля jobs формы, пусто - DefaultJobs
+func (s *Server[T]) jobs(r *http.Request) []platform.JobID {
+	raw := r.URL.Query().Get("jobs")
+	if raw == "" && r.MultipartForm != nil {
+		raw = r.FormValue("jobs")
+	}
+	var res []platform.JobID
+	for _, jobID := range strings.Split(raw, ",") {
+		if jobID = strings.TrimSpace(jobID); jobID != "" {
+			res = append(res, platform.JobID(jobID))
+		}
+	}
+	return res
+}
+
+func (s *Server[T]) delete(w http.ResponseWriter, info platform.PipelineInfo) {
+	if info.Status == platform.PipelinePending || info.Status == platform.PipelineRunning {
+		if err := s.Platform.CancelPipeline(info.ID); err != nil {
+			writeError(w, http.StatusConflict, err)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+	if err := s.remove(info.ID); err != nil {
+		writeError(w, http.StatusConflict, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server[T]) remove(pipeID platform.PipelineID) error {
+	if err := s.Platform.RemovePipeline(pipeID); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	delete(s.results, pipeID)
+	s.mu.Unlock()
+	return nil
+}
+
+// cleanup - выкидывает результаты, которые лежат дольше Retention
+func (s *Server[T]) cleanup() {
+	if s.Retention <= 0 {
+		return
+	}
+	for _, info := range s.Platform.ListPipelines() {
+		if info.FinishedAt.IsZero() || time.Since(info.FinishedAt) < s.Retention || s.get(info.ID) == nil {
+			continue
+		}
+		_ = s.remove(info.ID)
+	}
+}
+
+func (s *Server[T]) get(pipeID platform.PipelineID) *result {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.results[pipeID]
+}
+
+type statusResponse struct {
+	ID         platform.PipelineID       `json:"id"`
+	Status     string                    `json:"status"`
+	Jobs       []platform.JobID          `json:"jobs"`
+	Progress   platform.PipelineProgress `json:"progress,omitempty"`
+	CreatedAt  time.Time                 `json:"created_at"`
+	StartedAt  *time.Time                `json:"started_at,omitempty"`
+	FinishedAt *time.Time                `json:"finished_at,omitempty"`
+	Error      string                    `json:"error,omitempty"`
+	Summary    map[goexel.Severity]int   `json:"summary,omitempty"`
+	HasErrors  bool                      `json:"has_errors"`
+}
+
+func (s *Server[T]) status(info platform.PipelineInfo) statusResponse {
+	res := statusResponse{
+		ID:        info.ID,
+		Status:    info.Status.String(),
+		Jobs:      info.Jobs,
+		CreatedAt: info.CreatedAt,
+	}
+	if !info.StartedAt.IsZero() {
+		res.StartedAt = &info.StartedAt
+	}
+	if !info.FinishedAt.IsZero() {
+		res.FinishedAt = &info.FinishedAt
+	}
+	if info.Err != nil {
+		res.Error = info.Err.Error()
+	}
+	if progress, err := s.Platform.GetProgress(info.ID); err == nil {
+		res.Progress = progress
+	}
+
+	// пайплайн уже закончился, а файл и отчет еще собираются: до тех пор для клиента валидация идет
+	stored := s.get(info.ID)
+	switch {
+	case stored.finished():
+		res.Summary = stored.report.BySeverity
+		res.HasErrors = stored.errors
+	case info.Status != platform.PipelinePending:
+		res.Status = platform.PipelineRunning.String()
+	}
+	return res
+}
+
+func contentType(format goexel.Format) string {
+	switch format {
+	case goexel.FormatCSV:
+		return "text/csv; charset=utf-8"
+	case goexel.FormatTSV:
+		return "text/tab-separated-values; charset=utf-8"
+	}
+	return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+}
+
+func writeJSON(w http.ResponseWriter, code int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func writeError(w http.ResponseWriter, code int, err error) {
+	writeJSON(w, code, map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,146 @@
+package server_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+	goxlsx "gitlab.ozon.ru/express/platform/lib/go-xlsx"
+	"gitlab.ozon.ru/validator/broadcaster"
+	"gitlab.ozon.ru/validator/goexel"
+	"gitlab.ozon.ru/validator/goexel/goexeltest"
+	"gitlab.ozon.ru/validator/platform"
+	"gitlab.ozon.ru/validator/server"
+)
+
+type skuRow struct {
+	Sku goxlsx.String `xlsx:"SKU"`
+}
+
+type emptySkuJob struct {
+	*platform.JobWrapper
+}
+
+func (j *emptySkuJob) Run(ctx context.Context) error {
+	return platform.RunByLine(ctx, j.JobWrapper, func(c context.Context, register *goexel.FileCellRegisterer, r *skuRow) platform.JobResult {
+		if r.Sku.Value == "" {
+			register.RegisterCommentByValue(&r.Sku, "пустой СКУ")
+		}
+		return platform.JobResult{}
+	})
+}
+
+func (j *emptySkuJob) GetDepIDs() []platform.JobID { return nil }
+func (j *emptySkuJob) GetID() platform.JobID       { return "empty_sku" }
+func (j *emptySkuJob) GetType() platform.JobType   { return platform.Common }
+func (j *emptySkuJob) Create() platform.Job {
+	return &emptySkuJob{JobWrapper: j.JobWrapper.Create()}
+}
+
+func newBook(t *testing.T, skus ...string) []byte {
+	t.Helper()
+	book := goexeltest.SkuBook(t, skus...)
+	// комментарий, чтобы строка с пустым СКУ не считалась пустой
+	for i := range skus {
+		_ = book.SetCellStr("Sheet1", "B"+strconv.Itoa(i+2), "x")
+	}
+	return goexeltest.Bytes(t, book)
+}
+
+type statusResponse struct {
+	ID        string         `json:"id"`
+	Status    string         `json:"status"`
+	Summary   map[string]int `json:"summary"`
+	HasErrors bool           `json:"has_errors"`
+}
+
+func decode(t *testing.T, resp *http.Response, code int, dst interface{}) {
+	t.Helper()
+	defer resp.Body.Close()
+	if resp.StatusCode != code {
+		t.Fatalf("expected %d, got %d", code, resp.StatusCode)
+	}
+	if dst != nil {
+		if err := json.NewDecoder(resp.Body).Decode(dst); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestServer(t *testing.T) {
+	plat := platform.NewPlatform(5*time.Second, platform.JobPool{JobMap: map[platform.JobID]platform.Job{}})
+	_ = plat.AddJob(&emptySkuJob{JobWrapper: &platform.JobWrapper{ResChan: &broadcaster.Broadcaster[platform.JobResult]{}}})
+	srv := httptest.NewServer(server.NewServer[skuRow](plat, []platform.JobID{"empty_sku"}).Handler())
+	defer srv.Close()
+
+	resp, err := http.Post(srv.URL+"/validations?jobs=unknown", "application/octet-stream", bytes.NewReader(newBook(t, "1")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	decode(t, resp, http.StatusBadRequest, nil)
+
+	// две загрузки сразу, у каждой свои копии джоб
+	ids := make([]string, 0, 2)
+	for _, book := range [][]byte{newBook(t, "1", "", "3"), newBook(t, "1")} {
+		resp, err = http.Post(srv.URL+"/validations?name=promo.xlsx", "application/octet-stream", bytes.NewReader(book))
+		if err != nil {
+			t.Fatal(err)
+		}
+		created := statusResponse{}
+		decode(t, resp, http.StatusAccepted, &created)
+		ids = append(ids, created.ID)
+	}
+
+	results := make([]statusResponse, len(ids))
+	for i, id := range ids {
+		for deadline := time.Now().Add(5 * time.Second); results[i].Status != "finished"; {
+			if time.Now().After(deadline) {
+				t.Fatalf("validation %s is still %s", id, results[i].Status)
+			}
+			resp, err = http.Get(srv.URL + "/validations/" + id)
+			if err != nil {
+				t.Fatal(err)
+			}
+			decode(t, resp, http.StatusOK, &results[i])
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+	if !results[0].HasErrors || results[0].Summary["error"] != 1 || results[1].HasErrors {
+		t.Fatalf("unexpected results: %+v", results)
+	}
+
+	resp, err = http.Get(srv.URL + "/validations/" + ids[0] + "/report")
+	if err != nil {
+		t.Fatal(err)
+	}
+	report := goexel.Report{}
+	decode(t, resp, http.StatusOK, &report)
+	if report.Total != 1 || report.Findings[0].Cell != "A3" {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+
+	resp, err = http.Get(srv.URL + "/validations/" + ids[0] + "/file")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if _, err = excelize.OpenReader(resp.Body); err != nil {
+		t.Fatalf("annotated file is not a workbook: %v", err)
+	}
+
+	req, _ := http.NewRequest(http.MethodDelete, srv.URL+"/validations/"+ids[0], nil)
+	if resp, err = http.DefaultClient.Do(req); err != nil {
+		t.Fatal(err)
+	}
+	decode(t, resp, http.StatusNoContent, nil)
+	if resp, err = http.Get(srv.URL + "/validations/" + ids[0]); err != nil {
+		t.Fatal(err)
+	}
+	decode(t, resp, http.StatusNotFound, nil)
+}
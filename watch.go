@@ -0,0 +1,247 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"path"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/fsnotify/fsnotify"
+	"gitlab.ozon.ru/validator/platform"
+)
+
+const (
+	watchDone   = "done"
+	watchFailed = "failed"
+)
+
+// watch - следит за папкой и валидирует каждый файл, который в нее положили:
+// watch [flags] /path/to/inbox [/path/to/rules.yaml].
+// Размеченный файл и отчет пишутся в --out, сам файл уезжает в inbox/done или inbox/failed
+func watch(args []string) {
+	var (
+		opts cliOptions
+		poll time.Duration
+	)
+	fs := flag.NewFlagSet(path.Base(os.Args[0])+" watch", flag.ExitOnError)
+	fs.StringVar(&opts.jobs, "jobs", "", "comma separated jobs to run instead of the default ones")
+	fs.StringVar(&opts.excludeJobs, "exclude-jobs", "", "comma separated jobs to skip")
+	fs.StringVar(&opts.outDir, "out", "", "folder for annotated files and reports, inbox/out by default")
+	fs.StringVar(&opts.sheet, "sheet", "", "validate only this sheet of the xlsx files")
+	fs.DurationVar(&opts.timeout, "timeout", time.Minute, "validation time limit per file, 0 - no limit")
+	fs.StringVar(&opts.skuCatalog, "sku-catalog", "", skuCatalogUsage)
+	fs.StringVar(&opts.cacheDir, "cache-dir", "", "cache row results and found skus here, so revalidating a corrected file recomputes only changed rows")
+	fs.DurationVar(&poll, "poll", 2*time.Second, "how often the folder is rescanned, a file is taken once it hasn't changed for this long")
+	_ = fs.Parse(args)
+	if fs.NArg() < 1 || poll <= 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	var rulesPath string
+	if fs.NArg() > 1 {
+		rulesPath = fs.Arg(1)
+	}
+
+	inbox := fs.Arg(0)
+	if opts.outDir == "" {
+		opts.outDir = filepath.Join(inbox, "out")
+	}
+	for _, dir := range []string{opts.outDir, filepath.Join(inbox, watchDone), filepath.Join(inbox, watchFailed)} {
+		if err := os.MkdirAll(dir, 0777); err != nil {
+			log.Fatalf("failed to create %s: %s", dir, color.RedString(err.Error()))
+		}
+	}
+
+	store := resultStore(opts.cacheDir)
+	plat, defaultJobs := newPlatform(rulesPath, cachedSkuCatalog(newSkuCatalog(opts.skuCatalog), store, opts.skuCatalog))
+	plat.ValidationLimit = opts.timeout
+	plat.ResultCache = store
+	jobIDs, err := selectJobs(plat, defaultJobs, opts.jobs, opts.excludeJobs)
+	if err != nil {
+		log.Fatalf(color.RedString("failed to select jobs: ") + err.Error())
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	plan, err := plat.Plan(ctx, jobIDs)
+	if err != nil {
+		log.Fatalf(color.RedString("failed to create pipeline: ") + err.Error())
+	}
+	log.Printf(boundedStrLayout, plan.Text())
+
+	w := &inboxWatcher{
+		plat:   plat,
+		jobIDs: jobIDs,
+		inbox:  inbox,
+		opts:   opts,
+		settle: poll,
+		seen:   make(map[string]fileState),
+	}
+	log.Printf(boundedStrLayout, fmt.Sprintf("watching %s, results go to %s", color.GreenString(inbox), color.GreenString(opts.outDir)))
+	w.run(ctx)
+}
+
+type inboxWatcher struct {
+	plat   *platform.Platform
+	jobIDs []platform.JobID
+	inbox  string
+	opts   cliOptions
+	// settle - столько файл должен не меняться, чтобы его взяли: его могут еще копировать
+	settle time.Duration
+	// seen - каким файл был на прошлом скане и с какого момента он такой
+	seen map[string]fileState
+}
+
+type fileState struct {
+	size    int64
+	modTime time.Time
+	since   time.Time
+	// stuck - файл проверен, но из inbox его убрать не вышло, заново берем только если он изменится
+	stuck bool
+}
+
+// run - сканирует папку по событиям fsnotify и раз в settle,
+// на сетевых папках событий может не быть, тогда остается только опрос
+func (w *inboxWatcher) run(ctx context.Context) {
+	ticker := time.NewTicker(w.settle)
+	defer ticker.Stop()
+
+	var (
+		events <-chan fsnotify.Event
+		errs   <-chan error
+	)
+	watcher, err := fsnotify.NewWatcher()
+	if err == nil {
+		if err = watcher.Add(w.inbox); err != nil {
+			_ = watcher.Close()
+		}
+	}
+	if err != nil {
+		log.Printf("fsnotify is unavailable, polling every %s: %v", w.settle, err)
+	} else {
+		defer watcher.Close()
+		events, errs = watcher.Events, watcher.Errors
+	}
+
+	w.scan(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case event, ok := <-events:
+			if !ok {
+				events = nil
+				continue
+			}
+			if event.Op&(fsnotify.Create|fsnotify.Write|fsnotify.Rename) == 0 {
+				continue
+			}
+		case err, ok := <-errs:
+			if !ok {
+				errs = nil
+				continue
+			}
+			log.Printf("fsnotify error: %v", err)
+			continue
+		}
+		w.scan(ctx)
+	}
+}
+
+// scan - берет в работу файлы, которые не менялись хотя бы settle
+func (w *inboxWatcher) scan(ctx context.Context) {
+	files, err := batchFiles(w.inbox)
+	if err != nil {
+		log.Printf("failed to list %s: %s", w.inbox, color.RedString(err.Error()))
+		return
+	}
+
+	now := time.Now()
+	present := make(map[string]struct{}, len(files))
+	for _, file := range files {
+		if ctx.Err() != nil {
+			return
+		}
+		info, err := os.Stat(file)
+		if err != nil {
+			continue
+		}
+		present[file] = struct{}{}
+
+		state, exists := w.seen[file]
+		if !exists || state.size != info.Size() || !state.modTime.Equal(info.ModTime()) {
+			w.seen[file] = fileState{size: info.Size(), modTime: info.ModTime(), since: now}
+			continue
+		}
+		if state.stuck || now.Sub(state.since) < w.settle {
+			continue
+		}
+		if err = w.process(ctx, file); err != nil {
+			log.Printf("failed to move %s: %s", file, color.RedString(err.Error()))
+			state.stuck = true
+			w.seen[file] = state
+			continue
+		}
+		delete(w.seen, file)
+	}
+	for file := range w.seen {
+		if _, exists := present[file]; !exists {
+			delete(w.seen, file)
+		}
+	}
+}
+
+// process - валидирует файл и убирает его из inbox, в failed попадают и файлы с ошибками валидации.
+// Ошибка - файл не удалось перенести, он так и лежит во входящих
+func (w *inboxWatcher) process(ctx context.Context, file string) error {
+	res, err := validateFile(ctx, w.plat, w.jobIDs, file, w.opts, false)
+	// остановили посреди валидации - файл остается во входящих до следующего запуска
+	if ctx.Err() != nil {
+		return nil
+	}
+
+	dest := watchDone
+	switch {
+	case err != nil:
+		dest = watchFailed
+		log.Printf("%s: %s", file, color.RedString(err.Error()))
+		// отчета у такого файла нет, поэтому причину кладем рядом с результатами
+		name := filepath.Base(file)
+		errFile := filepath.Join(w.opts.outDir, strings.TrimSuffix(name, filepath.Ext(name))+"_error.txt")
+		//nolint:gosec
+		if err = os.WriteFile(errFile, []byte(err.Error()+"\n"), 0666); err != nil {
+			log.Printf("failed to save error: %s", color.RedString(err.Error()))
+		}
+	case res.hasErrors:
+		dest = watchFailed
+		log.Printf("%s: %s, findings: %d", file, color.RedString(batchFailed), res.report.Total)
+	default:
+		log.Printf("%s: %s, findings: %d", file, color.GreenString(batchPassed), res.report.Total)
+	}
+
+	moved, err := moveFile(file, filepath.Join(w.inbox, dest))
+	if err != nil {
+		return err
+	}
+	log.Printf("%s has been moved to %s", file, color.BlackString(moved))
+	return nil
+}
+
+// moveFile - переносит файл в dir, если там уже есть файл с таким именем, к имени дописывается время
+func moveFile(file, dir string) (string, error) {
+	name := filepath.Base(file)
+	dest := filepath.Join(dir, name)
+	if _, err := os.Stat(dest); err == nil {
+		ext := filepath.Ext(name)
+		dest = filepath.Join(dir, fmt.Sprintf("%s_%s%s", strings.TrimSuffix(name, ext), time.Now().Format("20060102-150405"), ext))
+	}
+	return dest, os.Rename(file, dest)
+}
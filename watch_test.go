@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gitlab.ozon.ru/validator/platform"
+)
+
+func newInboxWatcher(t *testing.T, settle time.Duration) *inboxWatcher {
+	t.Helper()
+	inbox := t.TempDir()
+	opts := cliOptions{outDir: filepath.Join(inbox, "out")}
+	for _, dir := range []string{opts.outDir, filepath.Join(inbox, watchDone), filepath.Join(inbox, watchFailed)} {
+		if err := os.MkdirAll(dir, 0777); err != nil {
+			t.Fatal(err)
+		}
+	}
+	plat, _ := newPlatform("", nil)
+	return &inboxWatcher{
+		plat:   plat,
+		jobIDs: []platform.JobID{"Валидный ли Ску"},
+		inbox:  inbox,
+		opts:   opts,
+		settle: settle,
+		seen:   make(map[string]fileState),
+	}
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func TestInboxWatcherScan(t *testing.T) {
+	const settle = 50 * time.Millisecond
+	w := newInboxWatcher(t, settle)
+	ctx := context.Background()
+
+	file := writeEntryBook(t, w.inbox, "promo.xlsx", 0, "5", "7")
+	broken := filepath.Join(w.inbox, "broken.xlsx")
+	if err := os.WriteFile(broken, []byte("not a workbook"), 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	// только что появившиеся файлы могут еще копировать, их не трогаем
+	w.scan(ctx)
+	w.scan(ctx)
+	if !exists(file) || !exists(broken) {
+		t.Fatal("files are taken before they settled")
+	}
+
+	// файл поменялся - отсчет начинается заново
+	time.Sleep(settle)
+	if err := os.WriteFile(broken, []byte("still not a workbook"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	w.scan(ctx)
+	if exists(file) || !exists(broken) {
+		t.Fatal("only the settled file must be taken")
+	}
+	if !exists(filepath.Join(w.inbox, watchDone, "promo.xlsx")) || !exists(filepath.Join(w.opts.outDir, "promo_report.json")) {
+		t.Fatal("validated file is not moved to done or has no report")
+	}
+
+	time.Sleep(settle)
+	w.scan(ctx)
+	if exists(broken) || !exists(filepath.Join(w.inbox, watchFailed, "broken.xlsx")) || !exists(filepath.Join(w.opts.outDir, "broken_error.txt")) {
+		t.Fatal("broken file is not moved to failed with its error")
+	}
+	if len(w.seen) != 0 {
+		t.Fatalf("processed files are still tracked: %v", w.seen)
+	}
+	// watch работает бесконечно, пайплайны обработанных файлов не должны копиться
+	if pipes := w.plat.ListPipelines(); len(pipes) != 0 {
+		t.Fatalf("%d pipelines are left in the registry", len(pipes))
+	}
+}
+
+func TestInboxWatcherKeepsStuckFile(t *testing.T) {
+	const settle = 20 * time.Millisecond
+	w := newInboxWatcher(t, settle)
+	ctx := context.Background()
+	file := writeEntryBook(t, w.inbox, "promo.xlsx", 0, "5")
+	report := filepath.Join(w.opts.outDir, "promo_report.json")
+
+	// done не папка, перенести туда файл не выйдет
+	done := filepath.Join(w.inbox, watchDone)
+	if err := os.Remove(done); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(done, nil, 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	w.scan(ctx)
+	time.Sleep(settle)
+	w.scan(ctx)
+	if !exists(file) || !exists(report) {
+		t.Fatal("file must be validated and left in the inbox")
+	}
+
+	// пока файл не меняется, заново его не проверяем
+	if err := os.Remove(report); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(settle)
+	w.scan(ctx)
+	if exists(report) {
+		t.Fatal("file that failed to move is validated again")
+	}
+
+	// исправленный файл снова берется в работу
+	writeEntryBook(t, w.inbox, "promo.xlsx", 0, "7")
+	w.scan(ctx)
+	time.Sleep(settle)
+	w.scan(ctx)
+	if !exists(report) {
+		t.Fatal("changed file is not validated again")
+	}
+}
+
+func TestMoveFile(t *testing.T) {
+	inbox, dest := t.TempDir(), t.TempDir()
+	for i := 0; i < 2; i++ {
+		file := filepath.Join(inbox, "promo.xlsx")
+		if err := os.WriteFile(file, []byte{byte(i)}, 0666); err != nil {
+			t.Fatal(err)
+		}
+		moved, err := moveFile(file, dest)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if i == 0 && moved != filepath.Join(dest, "promo.xlsx") {
+			t.Fatalf("unexpected destination %s", moved)
+		}
+		// второй файл с тем же именем не затирает первый
+		if i == 1 && (moved == filepath.Join(dest, "promo.xlsx") || filepath.Ext(moved) != ".xlsx") {
+			t.Fatalf("unexpected destination %s", moved)
+		}
+		if exists(file) || !exists(moved) {
+			t.Fatalf("%s is not moved to %s", file, moved)
+		}
+	}
+	entries, err := os.ReadDir(dest)
+	if err != nil || len(entries) != 2 {
+		t.Fatalf("expected 2 files in %s, got %d (%v)", dest, len(entries), err)
+	}
+}